@@ -0,0 +1,575 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect",
+	Short: "Find the commit that introduced a bug",
+	Long: `Guided git bisect: pick a bad commit and a known good one from the commit
+list, then mark each commit smak checks out as good, bad or skipped until the
+first bad commit is found, which opens in the commit detail view.
+
+Running it again while a bisect is in progress picks up where it left off.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBisect(cmd, nil)
+	},
+}
+
+var bisectRunCmd = &cobra.Command{
+	Use:   "run <script> [args...]",
+	Short: "Bisect automatically with a test script",
+	Long: `Like smak bisect, but once the range is picked the script is run on every commit
+to test: exit code 0 marks it good, 125 skips it and any other code below 128
+marks it bad. Put
+the script after -- when it takes flags, e.g. smak bisect run -- go test -run TestX ./...`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runBisect(cmd, args)
+	},
+}
+
+func runBisect(cmd *cobra.Command, script []string) {
+	if err := checkGitRepo(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	state, err := internal.GetBisectState()
+	if err != nil {
+		fmt.Printf("Error reading the bisect state: %v\n", err)
+		return
+	}
+	var commits []internal.Commit
+	if !state.Active {
+		if commits, err = internal.GetCommits(); err != nil {
+			fmt.Printf("Error getting commits: %v\n", err)
+			return
+		}
+	}
+
+	model := newBisectModel(commits, state, script, splitDiffDefault(cmd))
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		log.Fatalf("Error running program: %v", err)
+	}
+
+	if m := final.(bisectModel); m.state.Active && !m.state.Done() {
+		fmt.Println("The bisect is still in progress: run smak bisect to continue, or git bisect reset to stop")
+	}
+}
+
+// bisectPhase is the step of the bisect the view is at.
+type bisectPhase int
+
+const (
+	bisectPickBad bisectPhase = iota
+	bisectPickGood
+	bisectStepping
+)
+
+type bisectModel struct {
+	phase   bisectPhase
+	list    list.Model
+	commits []internal.Commit
+	graph   []internal.GraphRow
+	bad     string
+	width   int
+	height  int
+
+	state   *internal.BisectState
+	current *internal.Commit
+	// suspects holds the first bad commit, or the commits it may be when
+	// skipped commits keep the bisect from deciding
+	suspects []*internal.Commit
+	// total is the most commits seen left to test, the base of the progress bar
+	total int
+	// shownFirstBad is the first bad commit already opened in the detail view
+	shownFirstBad string
+
+	// script runs automatically once the bisect has started
+	script      []string
+	enterScript bool
+	scriptInput textinput.Model
+
+	// detail shows commits using the commit browser's detail view
+	detail commitModel
+
+	// running describes a bisect run in progress and progress holds the last
+	// line it printed. stopping is set once the run was signalled to stop, and
+	// quitAfterRun quits as soon as it has.
+	running      string
+	progress     string
+	events       chan tea.Msg
+	runCmd       *exec.Cmd
+	stopping     bool
+	quitAfterRun bool
+	startCmd     tea.Cmd
+
+	confirmReset bool
+	message      string
+	isError      bool
+}
+
+func newBisectModel(commits []internal.Commit, state *internal.BisectState, script []string, splitDiff bool) bisectModel {
+	graph := internal.BuildGraph(commits)
+	items := make([]list.Item, len(commits))
+	for i, commit := range commits {
+		items[i] = commitItem{commit: commit, graph: graph[i]}
+	}
+
+	l := list.New(items, commitDelegate{DefaultDelegate: list.NewDefaultDelegate()}, 0, 0)
+	l.Title = "Pick the bad commit, where the bug shows up"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	m := bisectModel{
+		list:    l,
+		commits: commits,
+		graph:   graph,
+		state:   state,
+		script:  script,
+		detail:  newCommitModel(nil, false, splitDiff),
+	}
+	if state.Active {
+		m = m.refresh()
+		m.phase = bisectStepping
+		m, m.startCmd = m.startScript()
+	}
+	return m
+}
+
+func (m bisectModel) Init() tea.Cmd {
+	return m.startCmd
+}
+
+// refresh reloads the bisect state and the commit being tested, opening
+// the first bad commit once it is found.
+func (m bisectModel) refresh() bisectModel {
+	state, err := internal.GetBisectState()
+	if err != nil {
+		m.message, m.isError = "Error reading the bisect state: "+err.Error(), true
+		return m
+	}
+	m.state = state
+	m.total = max(m.total, state.Remaining)
+
+	m.current = nil
+	if state.Current != "" {
+		if details, err := internal.GetCommitDetails(state.Current); err == nil {
+			m.current = details
+		}
+	}
+
+	m.suspects = nil
+	suspects := state.Suspects
+	if state.FirstBad != "" {
+		suspects = []string{state.FirstBad}
+	}
+	for _, hash := range suspects {
+		if details, err := internal.GetCommitDetails(hash); err == nil {
+			m.suspects = append(m.suspects, details)
+		}
+	}
+
+	if state.FirstBad != "" && state.FirstBad != m.shownFirstBad {
+		m.shownFirstBad = state.FirstBad
+		m.detail = m.detail.openCommit(state.FirstBad)
+	}
+	return m
+}
+
+// startScript runs the script given on the command line, if any, once the
+// bisect has a range to work on.
+func (m bisectModel) startScript() (bisectModel, tea.Cmd) {
+	if len(m.script) == 0 || m.state.Done() || m.state.Bad == "" || len(m.state.Good) == 0 {
+		return m, nil
+	}
+	return m.startRun("Running " + strings.Join(m.script, " "))
+}
+
+// startRun hands the bisect over to m.script in the background.
+func (m bisectModel) startRun(label string) (bisectModel, tea.Cmd) {
+	m.runCmd = internal.BisectRunCommand(m.script)
+	events, wait := runGitAsync(m.runCmd)
+	m.running, m.progress, m.events = label, "", events
+	return m, wait
+}
+
+// stopRun interrupts the bisect run. The run still reports its end, which
+// is when the view quits if quit is set.
+func (m bisectModel) stopRun(quit bool) bisectModel {
+	m.quitAfterRun = m.quitAfterRun || quit
+	if m.stopping {
+		return m
+	}
+	if err := internal.StopCommand(m.runCmd); err != nil {
+		m.message, m.isError = "Error stopping the run: "+err.Error(), true
+		return m
+	}
+	m.stopping = true
+	return m
+}
+
+// setItems fills the list with the commits, marking the picked bad one.
+func (m bisectModel) setItems() bisectModel {
+	items := make([]list.Item, len(m.commits))
+	for i, commit := range m.commits {
+		items[i] = commitItem{commit: commit, graph: m.graph[i], selected: commit.Hash == m.bad}
+	}
+	m.list.SetItems(items)
+	return m
+}
+
+func (m bisectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case gitProgressMsg:
+		m.progress = string(msg)
+		return m, waitForGit(m.events)
+
+	case gitDoneMsg:
+		stopped := m.stopping
+		m.running, m.events, m.progress, m.runCmd, m.stopping = "", nil, "", nil, false
+		if m.quitAfterRun {
+			return m, tea.Quit
+		}
+		m = m.refresh()
+		switch {
+		case stopped && !m.state.Done():
+			m.message, m.isError = fmt.Sprintf("Stopped the run at %s: mark it with g/b/s or press R to run again", shortHash(m.state.Current)), false
+		case msg.err != nil && !m.state.Done():
+			// The run also fails when skipped commits leave several suspects
+			m.message, m.isError = "Bisect run stopped: "+msg.err.Error(), true
+		}
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		helpHeight := 4
+		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		updated, _ := m.detail.Update(msg)
+		m.detail = updated.(commitModel)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running != "" {
+			// Quitting first stops the run, so that it does not go on testing
+			// and checking out commits behind the user's back
+			switch msg.String() {
+			case "x":
+				return m.stopRun(false), nil
+			case "ctrl+c":
+				return m.stopRun(true), nil
+			}
+			return m, nil
+		}
+		if m.detail.showDiff {
+			updated, cmd := m.detail.Update(msg)
+			m.detail = updated.(commitModel)
+			return m, cmd
+		}
+		if m.enterScript {
+			return m.updateScript(msg)
+		}
+		if m.confirmReset {
+			m.confirmReset = false
+			if msg.String() == "y" {
+				return m.reset()
+			}
+			return m, nil
+		}
+
+		m.message = ""
+		if m.phase == bisectStepping {
+			return m.updateStepping(msg)
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			if m.phase == bisectPickGood {
+				m = m.selectHash(m.bad)
+				m.bad = ""
+				m.phase = bisectPickBad
+				m.list.Title = "Pick the bad commit, where the bug shows up"
+				return m.setItems(), nil
+			}
+			return m, tea.Quit
+		case "enter":
+			if len(m.commits) == 0 {
+				return m, nil
+			}
+			hash := m.commits[m.list.Index()].Hash
+			if m.phase == bisectPickBad {
+				m.bad = hash
+				m.phase = bisectPickGood
+				m.list.Title = "Pick a good commit, from before the bug (bad: " + shortHash(hash) + ")"
+				m = m.setItems()
+				// The good commit is older, so start right below the bad one
+				m.list.Select(min(m.list.Index()+1, len(m.commits)-1))
+				return m, nil
+			}
+			if hash == m.bad {
+				m.message, m.isError = "The good commit has to be a different, older commit", true
+				return m, nil
+			}
+			return m.start(hash)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m bisectModel) selectHash(hash string) bisectModel {
+	for i, commit := range m.commits {
+		if commit.Hash == hash {
+			m.list.Select(i)
+		}
+	}
+	return m
+}
+
+func (m bisectModel) start(good string) (tea.Model, tea.Cmd) {
+	if err := internal.StartBisect(m.bad, good); err != nil {
+		m.message, m.isError = "Error starting the bisect: "+err.Error(), true
+		return m, nil
+	}
+	m = m.refresh()
+	m.phase = bisectStepping
+	if !m.state.Active {
+		// A dry run records the start without bisecting
+		m.message, m.isError = "The bisect did not start", true
+		return m, nil
+	}
+	return m.startScript()
+}
+
+func (m bisectModel) updateStepping(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+	case "r":
+		m.confirmReset = true
+		return m, nil
+	case "enter":
+		hash := m.state.FirstBad
+		if hash == "" {
+			hash = m.state.Current
+		}
+		if hash != "" {
+			m.detail = m.detail.openCommit(hash)
+		}
+		return m, nil
+	}
+
+	if m.state.Done() {
+		return m, nil
+	}
+	switch msg.String() {
+	case "g":
+		return m.mark(internal.BisectGood)
+	case "b":
+		return m.mark(internal.BisectBad)
+	case "s":
+		return m.mark(internal.BisectSkip)
+	case "R":
+		input := textinput.New()
+		input.Placeholder = "test command, e.g. go test ./..."
+		input.Width = max(20, m.width-10)
+		input.Focus()
+		m.scriptInput = input
+		m.enterScript = true
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+func (m bisectModel) mark(mark string) (tea.Model, tea.Cmd) {
+	tested := m.current
+	if err := internal.MarkBisect(mark); err != nil {
+		m.message, m.isError = "Error marking the commit "+mark+": "+err.Error(), true
+		return m, nil
+	}
+	m = m.refresh()
+	if tested != nil && !m.state.Done() {
+		m.message, m.isError = fmt.Sprintf("Marked %s %s as %s", shortHash(tested.Hash), tested.Message, mark), false
+	}
+	return m, nil
+}
+
+func (m bisectModel) updateScript(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.enterScript = false
+		return m, nil
+	case "enter":
+		command := strings.TrimSpace(m.scriptInput.Value())
+		if command == "" {
+			return m, nil
+		}
+		m.enterScript = false
+		m.script = []string{"sh", "-c", command}
+		return m.startRun("Running " + command)
+	}
+
+	var cmd tea.Cmd
+	m.scriptInput, cmd = m.scriptInput.Update(msg)
+	return m, cmd
+}
+
+func (m bisectModel) reset() (tea.Model, tea.Cmd) {
+	if err := internal.ResetBisect(); err != nil {
+		m.message, m.isError = "Error ending the bisect: "+err.Error(), true
+		return m, nil
+	}
+	m = m.refresh()
+	return m, tea.Quit
+}
+
+func (m bisectModel) View() string {
+	if m.detail.showDiff {
+		return m.detail.View()
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	var view, help string
+	if m.phase == bisectStepping {
+		view = m.renderStepping()
+		switch {
+		case m.state.Done():
+			help = "enter: first bad commit • r: end bisect • q: quit"
+		default:
+			help = "g: good • b: bad • s: skip • enter: diff • R: run a test command • r: end bisect • q: quit (keep bisecting later)"
+		}
+	} else {
+		view = m.list.View()
+		help = "↑↓: navigate • enter: pick • q: quit"
+		if m.phase == bisectPickGood {
+			help = "↑↓: navigate • enter: pick and start • esc: back • q: quit"
+		}
+	}
+
+	var status string
+	switch {
+	case m.enterScript:
+		status = "Command to run on each commit (exit 0: good, 125: skip, other: bad):\n" + m.scriptInput.View()
+		help = "enter: run • esc: cancel"
+	case m.confirmReset:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true).
+			Render("End the bisect and go back to the branch it started on? y: end • any other key: cancel")
+	case m.running != "":
+		running := m.running
+		if m.stopping {
+			running = "Stopping the run"
+		}
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Render(running + "...")
+		if m.progress != "" {
+			status += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(truncate(m.progress, max(20, m.width-2)))
+		}
+		help = "x: stop the run • ctrl+c: stop the run and quit"
+	case m.message != "" && m.isError:
+		status = errorStyle.Render(m.message)
+	case m.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	}
+	if status != "" {
+		view += "\n" + status
+	}
+
+	return view + "\n\n" + helpStyle.Width(m.width).Render(help)
+}
+
+func (m bisectModel) renderStepping() string {
+	state := m.state
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	goodStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	badStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	describe := func(commit *internal.Commit) string {
+		return titleStyle.Render(shortHash(commit.Hash)+" "+commit.Message) + "\n" +
+			dimStyle.Render(commit.Author+" • "+commit.Date.Format("2006-01-02 15:04"))
+	}
+
+	var lines []string
+	switch {
+	case !state.Active:
+		lines = append(lines, titleStyle.Render("No bisect in progress"))
+	case state.Done():
+		title := "Found the first bad commit"
+		if state.FirstBad == "" {
+			title = "Skipped commits are in the way; the first bad commit is one of:"
+		}
+		lines = append(lines, badStyle.Bold(true).Render(title))
+		for _, commit := range m.suspects {
+			lines = append(lines, "", describe(commit))
+		}
+	case state.Bad == "" || len(state.Good) == 0:
+		lines = append(lines, titleStyle.Render("Mark the checked out commit"), "",
+			dimStyle.Render("The bisect needs a bad and a good commit before it can narrow things down."))
+		if m.current != nil {
+			lines = append(lines, "", describe(m.current))
+		}
+	default:
+		steps := fmt.Sprintf("%d steps", state.Steps)
+		if state.Steps == 1 {
+			steps = "1 step"
+		}
+		lines = append(lines,
+			titleStyle.Render("Bisecting")+dimStyle.Render(fmt.Sprintf("  %d left to test, roughly %s", state.Remaining, steps)),
+			m.progressBar(),
+			"",
+			"Is this commit good or bad?",
+		)
+		if m.current != nil {
+			lines = append(lines, describe(m.current))
+		}
+	}
+
+	if state.Active {
+		lines = append(lines, "", badStyle.Render("bad: "+shortHash(state.Bad))+dimStyle.Render(" • ")+
+			goodStyle.Render(fmt.Sprintf("%d good", len(state.Good)))+dimStyle.Render(fmt.Sprintf(" • %d skipped", len(state.Skipped))))
+	}
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+// progressBar shows how much of the range has been ruled out in this session.
+func (m bisectModel) progressBar() string {
+	const width = 30
+	done := width
+	if m.total > 0 {
+		done = width * (m.total - m.state.Remaining) / m.total
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Render(strings.Repeat("█", done)) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("238")).Render(strings.Repeat("░", width-done))
+}
+
+func init() {
+	bisectCmd.PersistentFlags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
+	bisectCmd.AddCommand(bisectRunCmd)
+	rootCmd.AddCommand(bisectCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os/exec"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -30,7 +31,7 @@ var branchesCmd = &cobra.Command{
 			return
 		}
 
-		model := newBranchModel(branches)
+		model := newBranchModel(branches, splitDiffDefault(cmd))
 		p := tea.NewProgram(model, tea.WithAltScreen())
 
 		if _, err := p.Run(); err != nil {
@@ -40,9 +41,10 @@ var branchesCmd = &cobra.Command{
 }
 
 type branchItem struct {
-	branch         internal.Branch
-	isMergeSource  bool
-	isMarkedDelete bool
+	branch          internal.Branch
+	isMergeSource   bool
+	isMarkedDelete  bool
+	isCompareSource bool
 }
 
 func (i branchItem) Title() string {
@@ -50,6 +52,12 @@ func (i branchItem) Title() string {
 	if i.isMergeSource {
 		title += " (selected to merge from)"
 	}
+	if i.isCompareSource {
+		title += " (selected to compare)"
+	}
+	if i.branch.Worktree != "" {
+		title += " (checked out in " + i.branch.Worktree + ")"
+	}
 	return title
 }
 
@@ -80,6 +88,28 @@ type branchModel struct {
 		source string
 		target string
 	}
+	width     int
+	height    int
+	splitDiff bool
+
+	// compareSourceIdx is the branch marked with c to compare with another;
+	// compare is the open comparison, nil when there is none
+	compareSourceIdx int
+	compare          *compareModel
+
+	// message explains why the last action was refused, or how a push,
+	// pull or fetch ended (finished), possibly with an error (failed)
+	message  string
+	finished bool
+	failed   bool
+
+	// running describes the push, pull or fetch in progress and progress
+	// holds the last line git printed for it. success is the message shown
+	// when it works.
+	running  string
+	progress string
+	events   chan tea.Msg
+	success  string
 }
 
 type customDelegate struct {
@@ -107,6 +137,15 @@ func (d customDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 			titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
 			descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
 		}
+	} else if item.isCompareSource {
+		// Blue for the first branch of a comparison
+		if isCurrentlyNavigated {
+			titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true).Underline(true)
+			descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true)
+		} else {
+			titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("33"))
+			descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("33"))
+		}
 	} else if item.isMergeSource {
 		// Orange for merge source
 		if isCurrentlyNavigated {
@@ -132,7 +171,7 @@ func (d customDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 	fmt.Fprint(w, descStyle.Render(item.Description()))
 }
 
-func newBranchModel(branches []internal.Branch) branchModel {
+func newBranchModel(branches []internal.Branch, splitDiff bool) branchModel {
 	items := make([]list.Item, len(branches))
 	for i, branch := range branches {
 		items[i] = branchItem{
@@ -146,14 +185,16 @@ func newBranchModel(branches []internal.Branch) branchModel {
 
 	// Create model first so we can point to its fields
 	m := branchModel{
-		branches:        branches,
-		selectedIndexes: selectedIndexes,
-		confirmDelete:   false,
-		helpVisible:     true,
-		mergeMode:       false,
-		mergeSourceIdx:  -1,
-		showMergeResult: false,
-		mergeResult:     nil,
+		branches:         branches,
+		selectedIndexes:  selectedIndexes,
+		confirmDelete:    false,
+		helpVisible:      true,
+		mergeMode:        false,
+		mergeSourceIdx:   -1,
+		showMergeResult:  false,
+		mergeResult:      nil,
+		splitDiff:        splitDiff,
+		compareSourceIdx: -1,
 	}
 
 	// Create delegate
@@ -177,9 +218,10 @@ func (m branchModel) updateListItems() branchModel {
 	items := make([]list.Item, len(m.branches))
 	for i, branch := range m.branches {
 		items[i] = branchItem{
-			branch:         branch,
-			isMergeSource:  m.mergeMode && i == m.mergeSourceIdx,
-			isMarkedDelete: m.selectedIndexes[i],
+			branch:          branch,
+			isMergeSource:   m.mergeMode && i == m.mergeSourceIdx,
+			isMarkedDelete:  m.selectedIndexes[i],
+			isCompareSource: i == m.compareSourceIdx,
 		}
 	}
 	m.list.SetItems(items)
@@ -192,18 +234,58 @@ func (m branchModel) Init() tea.Cmd {
 
 func (m branchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case gitProgressMsg:
+		m.progress = string(msg)
+		return m, waitForGit(m.events)
+
+	case gitDoneMsg:
+		m.running, m.events, m.finished = "", nil, true
+		if msg.err != nil {
+			// The error already holds the lines that matter
+			m.message, m.failed, m.progress = msg.err.Error(), true, ""
+		} else {
+			m.message, m.failed = m.success, false
+		}
+		return m.reload(), nil
+
 	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.compare != nil {
+			compare, _ := m.compare.update(msg)
+			m.compare = &compare
+		}
 		if m.showMergeResult {
 			// Handle merge result window sizing
 			return m, nil
 		}
 		h, v := lipgloss.NewStyle().GetFrameSize()
-		// Reserve space for help text
-		helpHeight := 3
+		// Reserve space for help text and the message and progress lines
+		helpHeight := 5
 		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.compare != nil {
+			compare, cmd := m.compare.update(msg)
+			m.compare = &compare
+			if compare.closed {
+				m.compare = nil
+			}
+			return m, cmd
+		}
+		if m.running != "" {
+			// Only navigation is allowed until git finishes
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "up", "down", "k", "j", "pgup", "pgdown", "home", "end":
+				var cmd tea.Cmd
+				m.list, cmd = m.list.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+		m.message, m.finished, m.failed, m.progress = "", false, false, ""
 		if m.showMergeResult {
 			switch msg.String() {
 			case "enter":
@@ -240,6 +322,10 @@ func (m branchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "esc":
+			if m.compareSourceIdx >= 0 {
+				m.compareSourceIdx = -1
+				return m.updateListItems(), nil
+			}
 			if m.mergeMode {
 				// Exit merge mode
 				m.mergeMode = false
@@ -271,6 +357,50 @@ func (m branchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			return m, nil
+		case "p", "u":
+			if m.mergeMode || len(m.list.Items()) == 0 {
+				return m, nil
+			}
+			branch := m.branches[m.list.Index()]
+			if msg.String() == "p" {
+				target := internal.GetBranchPushTarget(branch.Name)
+				push, err := internal.PushBranchCommand(target)
+				if err != nil {
+					m.message = err.Error()
+					return m, nil
+				}
+				label := fmt.Sprintf("Pushing %s to %s/%s", branch.Name, target.Remote, target.RemoteRef)
+				if target.SetUpstream {
+					label += " (setting upstream)"
+				}
+				return m.startAction(label, "Pushed "+branch.Name, push)
+			}
+			current, _ := internal.CurrentBranch()
+			pull, err := internal.PullBranchCommand(branch, branch.Name == current)
+			if err != nil {
+				m.message = err.Error()
+				return m, nil
+			}
+			return m.startAction("Fast-forwarding "+branch.Name, "Fast-forwarded "+branch.Name, pull)
+		case "c":
+			if m.mergeMode || len(m.list.Items()) == 0 {
+				return m, nil
+			}
+			idx := m.list.Index()
+			switch m.compareSourceIdx {
+			case -1:
+				m.compareSourceIdx = idx
+			case idx:
+				m.compareSourceIdx = -1
+			default:
+				return m.openCompare(m.branches[m.compareSourceIdx].Name, m.branches[idx].Name), nil
+			}
+			return m.updateListItems(), nil
+		case "f":
+			if m.mergeMode {
+				return m, nil
+			}
+			return m.startAction("Fetching all remotes", "Fetched all remotes", internal.FetchAllCommand())
 		case "d":
 			if !m.mergeMode && len(m.list.Items()) > 0 {
 				idx := m.list.Index()
@@ -291,6 +421,10 @@ func (m branchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if targetIdx != m.mergeSourceIdx && targetIdx < len(m.branches) && m.mergeSourceIdx < len(m.branches) {
 						sourceBranch := m.branches[m.mergeSourceIdx].Name
 						targetBranch := m.branches[targetIdx].Name
+						if worktree := m.branches[targetIdx].Worktree; worktree != "" {
+							m.message = fmt.Sprintf("Cannot merge into %s: it is checked out in %s", targetBranch, worktree)
+							return m, nil
+						}
 
 						m.mergeBranches.source = sourceBranch
 						m.mergeBranches.target = targetBranch
@@ -318,6 +452,10 @@ func (m branchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				idx := m.list.Index()
 				if idx < len(m.branches) {
 					branchName := m.branches[idx].Name
+					if worktree := m.branches[idx].Worktree; worktree != "" {
+						m.message = fmt.Sprintf("%s is already checked out in %s (use smak w to switch there)", branchName, worktree)
+						return m, nil
+					}
 					err := internal.CheckoutBranch(branchName)
 					if err != nil {
 						log.Printf("Error checking out branch %s: %v", branchName, err)
@@ -334,6 +472,10 @@ func (m branchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m branchModel) View() string {
+	if m.compare != nil {
+		return m.compare.View()
+	}
+
 	if m.showMergeResult {
 		return m.renderMergeResult()
 	}
@@ -350,15 +492,31 @@ func (m branchModel) View() string {
 
 	view := m.list.View()
 
+	switch {
+	case m.running != "":
+		view += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Render(m.running+"...")
+	case m.message != "" && m.failed:
+		view += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	case m.message != "" && m.finished:
+		view += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	case m.message != "":
+		view += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render(m.message)
+	}
+	if m.progress != "" {
+		view += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(m.progress)
+	}
+
 	if m.helpVisible {
 		helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 		var helpText string
 		if m.mergeMode {
 			helpText = "↑↓: navigate • enter: merge into selected • esc: exit merge mode • q: quit"
+		} else if m.compareSourceIdx >= 0 {
+			helpText = "↑↓: navigate • c: compare with " + m.branches[m.compareSourceIdx].Name + " • esc: cancel • q: quit"
 		} else if len(m.selectedIndexes) > 0 {
 			helpText = "↑↓: navigate • enter: confirm • d: delete • m: merge • esc: clear • q: quit"
 		} else {
-			helpText = "↑↓: navigate • enter: checkout • d: delete • m: merge • esc/q: quit"
+			helpText = "↑↓: navigate • enter: checkout • d: delete • m: merge • c: compare • p: push • u: pull • f: fetch • esc/q: quit"
 		}
 		help := helpStyle.Render(helpText)
 		view += "\n\n" + help
@@ -367,6 +525,52 @@ func (m branchModel) View() string {
 	return view
 }
 
+// openCompare opens the comparison of two branches.
+func (m branchModel) openCompare(left, right string) branchModel {
+	m.compareSourceIdx = -1
+	m = m.updateListItems()
+	compare, err := newCompareModel(left, right, m.splitDiff, m.width, m.height)
+	if err != nil {
+		m.message = "Error comparing " + left + " and " + right + ": " + err.Error()
+		return m
+	}
+	m.compare = compare
+	return m
+}
+
+// startAction runs a push, pull or fetch in the background, streaming its
+// output into the view until it finishes.
+func (m branchModel) startAction(label, success string, cmd *exec.Cmd) (tea.Model, tea.Cmd) {
+	events, wait := runGitAsync(cmd)
+	m.running, m.success, m.events = label, success, events
+	return m, wait
+}
+
+// reload refreshes the branches and their ahead/behind counts in place,
+// keeping the highlighted branch.
+func (m branchModel) reload() branchModel {
+	branches, err := internal.GetBranches()
+	if err != nil {
+		m.message, m.failed = "Error reloading branches: "+err.Error(), true
+		return m
+	}
+
+	var highlighted string
+	if len(m.branches) > 0 {
+		highlighted = m.branches[m.list.Index()].Name
+	}
+	m.branches = branches
+	m.selectedIndexes = make(map[int]bool)
+	m.compareSourceIdx = -1
+	m = m.updateListItems()
+	for i, branch := range branches {
+		if branch.Name == highlighted {
+			m.list.Select(i)
+		}
+	}
+	return m
+}
+
 func (m branchModel) deleteBranches() (tea.Model, tea.Cmd) {
 	selectedBranches := []string{}
 	for idx := range m.selectedIndexes {
@@ -386,7 +590,8 @@ func (m branchModel) deleteBranches() (tea.Model, tea.Cmd) {
 	}
 
 	// Create new model with updated branches
-	newModel := newBranchModel(branches)
+	newModel := newBranchModel(branches, m.splitDiff)
+	newModel.width, newModel.height = m.width, m.height
 	// Preserve window size if we have it
 	if m.list.Width() > 0 && m.list.Height() > 0 {
 		newModel.list.SetSize(m.list.Width(), m.list.Height())
@@ -404,7 +609,8 @@ func (m branchModel) refreshBranchesAndReset() (tea.Model, tea.Cmd) {
 	}
 
 	// Create new model with updated branches
-	newModel := newBranchModel(branches)
+	newModel := newBranchModel(branches, m.splitDiff)
+	newModel.width, newModel.height = m.width, m.height
 	// Preserve window size if we have it
 	if m.list.Width() > 0 && m.list.Height() > 0 {
 		newModel.list.SetSize(m.list.Width(), m.list.Height())
@@ -418,6 +624,22 @@ func (m branchModel) renderMergeResult() string {
 		return "Error: No merge result to display"
 	}
 
+	var helpText string
+	if m.mergeResult.Success {
+		helpText = "enter: continue • esc: abort merge"
+	} else if m.mergeResult.HasConflicts {
+		helpText = "enter: resolve manually • esc: abort merge"
+	} else {
+		helpText = "enter: continue • esc: abort merge"
+	}
+
+	title := fmt.Sprintf("Merge %s → %s", m.mergeBranches.source, m.mergeBranches.target)
+	return renderResultPanel(title, "Merge", m.mergeResult, helpText)
+}
+
+// renderResultPanel shows the outcome of a merge-like operation (merge,
+// cherry-pick, revert) in a bordered box with its conflicted files.
+func renderResultPanel(title, operation string, result *internal.MergeResult, helpText string) string {
 	var content []string
 
 	// Title
@@ -426,7 +648,6 @@ func (m branchModel) renderMergeResult() string {
 		Bold(true).
 		Padding(1, 2)
 
-	title := fmt.Sprintf("Merge %s → %s", m.mergeBranches.source, m.mergeBranches.target)
 	content = append(content, titleStyle.Render(title))
 
 	// Status
@@ -434,27 +655,35 @@ func (m branchModel) renderMergeResult() string {
 		Padding(0, 2).
 		Margin(1, 0)
 
-	if m.mergeResult.Success {
+	if result.Success {
 		successStyle := statusStyle.Copy().Foreground(lipgloss.Color("46"))
-		content = append(content, successStyle.Render("✓ Merge completed successfully"))
-	} else if m.mergeResult.HasConflicts {
+		content = append(content, successStyle.Render("✓ "+operation+" completed successfully"))
+	} else if result.HasConflicts {
 		conflictStyle := statusStyle.Copy().Foreground(lipgloss.Color("196"))
-		content = append(content, conflictStyle.Render(fmt.Sprintf("✗ Merge conflicts detected (%d files)", m.mergeResult.ConflictCount)))
+		content = append(content, conflictStyle.Render(fmt.Sprintf("✗ %s conflicts detected (%d files)", operation, result.ConflictCount)))
 
 		// List conflict files
-		if len(m.mergeResult.ConflictFiles) > 0 {
+		if len(result.ConflictFiles) > 0 {
 			filesStyle := lipgloss.NewStyle().
 				Padding(0, 4).
 				Foreground(lipgloss.Color("243"))
 
 			content = append(content, filesStyle.Render("Conflicted files:"))
-			for _, file := range m.mergeResult.ConflictFiles {
+			for _, file := range result.ConflictFiles {
 				content = append(content, filesStyle.Render("• "+file))
 			}
 		}
 	} else {
 		errorStyle := statusStyle.Copy().Foreground(lipgloss.Color("196"))
-		content = append(content, errorStyle.Render("✗ Merge failed: "+m.mergeResult.ErrorMessage))
+		content = append(content, errorStyle.Render("✗ "+operation+" failed: "+result.ErrorMessage))
+	}
+
+	// In a dry run the output lists the commands that were skipped
+	if internal.DryRun && result.Output != "" {
+		outputStyle := lipgloss.NewStyle().
+			Padding(0, 2).
+			Foreground(lipgloss.Color("243"))
+		content = append(content, outputStyle.Render(result.Output))
 	}
 
 	// Help
@@ -463,15 +692,6 @@ func (m branchModel) renderMergeResult() string {
 		Padding(1, 2).
 		Margin(1, 0)
 
-	var helpText string
-	if m.mergeResult.Success {
-		helpText = "enter: continue • esc: abort merge"
-	} else if m.mergeResult.HasConflicts {
-		helpText = "enter: resolve manually • esc: abort merge"
-	} else {
-		helpText = "enter: continue • esc: abort merge"
-	}
-
 	content = append(content, helpStyle.Render(helpText))
 
 	// Create a bordered container
@@ -485,5 +705,6 @@ func (m branchModel) renderMergeResult() string {
 }
 
 func init() {
+	branchesCmd.Flags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
 	rootCmd.AddCommand(branchesCmd)
 }
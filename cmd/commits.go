@@ -2,11 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
-	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -30,7 +31,9 @@ var commitsCmd = &cobra.Command{
 			return
 		}
 
-		model := newCommitModel(commits)
+		showGraph, _ := cmd.Flags().GetBool("graph")
+
+		model := newCommitModel(commits, showGraph, splitDiffDefault(cmd))
 		p := tea.NewProgram(model, tea.WithAltScreen())
 
 		if _, err := p.Run(); err != nil {
@@ -41,8 +44,11 @@ var commitsCmd = &cobra.Command{
 
 var commitAmendCmd = &cobra.Command{
 	Use:   "am",
-	Short: "Stage all changes and amend to latest commit",
-	Long:  `Stage all unstaged changes and amend them to the latest commit with the same message.`,
+	Short: "Stage changes and amend to latest commit",
+	Long: `Stage all unstaged changes and amend them to the latest commit with the same message.
+
+Use -i to choose the files and hunks to amend, or --staged-only to amend only
+what is already staged. The message can be replaced with -m or edited with -e.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := checkGitRepo(); err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -50,30 +56,65 @@ var commitAmendCmd = &cobra.Command{
 		}
 
 		push, _ := cmd.Flags().GetBool("push")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		stagedOnly, _ := cmd.Flags().GetBool("staged-only")
+		message, _ := cmd.Flags().GetString("message")
+		edit, _ := cmd.Flags().GetBool("edit")
+
+		if interactive && stagedOnly {
+			fmt.Println("Error: --interactive and --staged-only cannot be used together")
+			return
+		}
+
+		if interactive {
+			confirmed, err := chooseAndStage("Choose changes to amend into the latest commit", "amend")
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if !confirmed {
+				fmt.Println("Amend cancelled")
+				return
+			}
+		}
 
-		if err := internal.StageAllAndAmend(push); err != nil {
-			fmt.Printf("Error amending commit: %v\n", err)
+		opts := internal.AmendOptions{
+			StageAll: !interactive && !stagedOnly,
+			Message:  message,
+			Edit:     edit,
+			Push:     push,
+		}
+		if err := internal.AmendCommit(opts); err != nil {
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
+		summary := "Successfully staged all changes and amended to latest commit"
+		if !opts.StageAll {
+			summary = "Successfully amended staged changes to latest commit"
+		}
 		if push {
-			fmt.Println("Successfully staged all changes, amended to latest commit, and pushed")
-		} else {
-			fmt.Println("Successfully staged all changes and amended to latest commit")
+			summary += " and pushed"
 		}
+		fmt.Println(summary)
 	},
 }
 
 type commitItem struct {
-	commit internal.Commit
+	commit   internal.Commit
+	graph    internal.GraphRow
+	selected bool
 }
 
 func (i commitItem) Title() string {
-	shortHash := i.commit.Hash
-	if len(shortHash) > 8 {
-		shortHash = shortHash[:8]
+	return fmt.Sprintf("%s - %s", shortHash(i.commit.Hash), i.commit.Message)
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
 	}
-	return fmt.Sprintf("%s - %s", shortHash, i.commit.Message)
+	return hash
 }
 
 func (i commitItem) Description() string {
@@ -87,21 +128,190 @@ func (i commitItem) FilterValue() string {
 type commitModel struct {
 	list        list.Model
 	commits     []internal.Commit
-	viewport    viewport.Model
+	graph       []internal.GraphRow
+	diff        diffView
 	showDiff    bool
-	currentDiff string
+	details     *internal.Commit
 	helpVisible bool
+	showGraph   bool
+	width       int
+	height      int
+
+	selected        map[string]bool
+	choosingBranch  bool
+	branchList      list.Model
+	showResult      bool
+	result          *internal.MergeResult
+	resultOperation string
+	resultTitle     string
+	// resultReturnTo is the branch to check out again once a cherry-pick that
+	// stopped on another branch is continued or aborted, and resultNote says
+	// so in the panel
+	resultReturnTo string
+	resultNote     string
+
+	rewording      bool
+	rewordHash     string
+	rewordInput    textarea.Model
+	rewordWarning  string
+	rewordOverride bool
+	rewordError    string
+
+	fixingUp     bool
+	fixupHash    string
+	fixupStaged  []string
+	fixupWarning string
+	fixupError   string
+
+	// compare is the open comparison of two commits, nil when there is none.
+	// It is a pointer because the comparison holds a commitModel itself.
+	compare      *compareModel
+	compareError string
+}
+
+// graphColors cycles through lanes so parallel lines of history are told apart.
+var graphColors = []lipgloss.Color{"33", "208", "46", "170", "226", "51", "196"}
+
+type commitDelegate struct {
+	list.DefaultDelegate
+	showGraph bool
+}
+
+func (d commitDelegate) Spacing() int {
+	// The graph has to be continuous between items
+	if d.showGraph {
+		return 0
+	}
+	return d.DefaultDelegate.Spacing()
+}
+
+func (d commitDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item, ok := listItem.(commitItem)
+	if !ok {
+		d.DefaultDelegate.Render(w, m, index, listItem)
+		return
+	}
+
+	var titleStyle, descStyle lipgloss.Style
+	if item.selected {
+		// Orange for commits selected for cherry-pick/revert
+		titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+		descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+		if index == m.Index() {
+			titleStyle = titleStyle.Bold(true).Underline(true)
+		}
+	} else if index == m.Index() {
+		titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+		descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	} else {
+		titleStyle = lipgloss.NewStyle()
+		descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	}
+
+	title := item.Title()
+	desc := item.Description()
+	width := m.Width() - 2
+
+	var titlePrefix, descPrefix string
+	if d.showGraph {
+		titlePrefix = renderGraphLine(item.graph.Node) + " "
+		descPrefix = renderGraphLine(item.graph.Link) + " "
+		width -= len([]rune(item.graph.Node)) + 1
+	} else {
+		titlePrefix = "  "
+		descPrefix = "  "
+	}
+
+	refs := renderRefs(item.commit.Refs)
+	if refs != "" {
+		width -= lipgloss.Width(refs) + 1
+		refs += " "
+	}
+
+	fmt.Fprint(w, titlePrefix+refs+titleStyle.Render(truncate(title, width)))
+	fmt.Fprint(w, "\n")
+	fmt.Fprint(w, descPrefix+descStyle.Render(truncate(desc, width)))
 }
 
-func newCommitModel(commits []internal.Commit) commitModel {
+func renderGraphLine(line string) string {
+	var b strings.Builder
+	for i, r := range []rune(line) {
+		if r == ' ' {
+			b.WriteRune(r)
+			continue
+		}
+		style := lipgloss.NewStyle().Foreground(graphColors[(i/2)%len(graphColors)])
+		if r == '●' {
+			style = style.Bold(true)
+		}
+		b.WriteString(style.Render(string(r)))
+	}
+	return b.String()
+}
+
+func renderRefs(refs []string) string {
+	if len(refs) == 0 {
+		return ""
+	}
+
+	var rendered []string
+	for _, ref := range refs {
+		var style lipgloss.Style
+		switch {
+		case strings.HasPrefix(ref, "HEAD"):
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("51")).Bold(true)
+		case strings.HasPrefix(ref, "tag: "):
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("226"))
+		case strings.Contains(ref, "/"):
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		default:
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+		}
+		rendered = append(rendered, style.Render(ref))
+	}
+
+	paren := lipgloss.NewStyle().Foreground(lipgloss.Color("226"))
+	return paren.Render("(") + strings.Join(rendered, paren.Render(", ")) + paren.Render(")")
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 {
+		return ""
+	}
+	if len(runes) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func (m commitModel) updateListItems() commitModel {
+	items := make([]list.Item, len(m.commits))
+	for i, commit := range m.commits {
+		items[i] = commitItem{
+			commit:   commit,
+			graph:    m.graph[i],
+			selected: m.selected[commit.Hash],
+		}
+	}
+	m.list.SetItems(items)
+	return m
+}
+
+func newCommitModel(commits []internal.Commit, showGraph, splitDiff bool) commitModel {
+	graph := internal.BuildGraph(commits)
 	items := make([]list.Item, len(commits))
 	for i, commit := range commits {
-		items[i] = commitItem{commit: commit}
+		items[i] = commitItem{commit: commit, graph: graph[i]}
 	}
 
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
-	delegate.Styles.SelectedDesc = lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	delegate := commitDelegate{
+		DefaultDelegate: list.NewDefaultDelegate(),
+		showGraph:       showGraph,
+	}
 
 	l := list.New(items, delegate, 0, 0)
 	l.Title = "Commits"
@@ -109,15 +319,15 @@ func newCommitModel(commits []internal.Commit) commitModel {
 	l.SetFilteringEnabled(false)
 	l.SetShowHelp(false)
 
-	vp := viewport.New(0, 0)
-	vp.Style = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
-
 	return commitModel{
 		list:        l,
 		commits:     commits,
-		viewport:    vp,
+		graph:       graph,
+		diff:        newDiffView(splitDiff),
 		showDiff:    false,
 		helpVisible: true,
+		showGraph:   showGraph,
+		selected:    make(map[string]bool),
 	}
 }
 
@@ -128,73 +338,149 @@ func (m commitModel) Init() tea.Cmd {
 func (m commitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.compare != nil {
+			compare, _ := m.compare.update(msg)
+			m.compare = &compare
+		}
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		helpHeight := 3
+		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		if m.choosingBranch {
+			m.branchList.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		}
 		if m.showDiff {
-			m.viewport.Width = msg.Width - 2
-			m.viewport.Height = msg.Height - 6
-		} else {
-			h, v := lipgloss.NewStyle().GetFrameSize()
-			helpHeight := 3
-			m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+			m = m.layoutDiff()
 		}
 		return m, nil
 
 	case tea.KeyMsg:
-		if m.showDiff {
+		if m.compare != nil {
+			compare, cmd := m.compare.update(msg)
+			m.compare = &compare
+			if compare.closed {
+				m.compare = nil
+			}
+			return m, cmd
+		}
+
+		if m.showResult {
+			return m.updateResult(msg)
+		}
+
+		if m.rewording {
+			return m.updateReword(msg)
+		}
+
+		if m.fixingUp {
+			return m.updateFixup(msg)
+		}
+
+		if m.choosingBranch {
 			switch msg.String() {
-			case "q", "ctrl+c", "esc":
-				m.showDiff = false
-				return m, nil
-			case "up", "k":
-				m.viewport.LineUp(1)
+			case "esc":
+				m.choosingBranch = false
 				return m, nil
-			case "down", "j":
-				m.viewport.LineDown(1)
-				return m, nil
-			case "pgup", "b":
-				m.viewport.ViewUp()
-				return m, nil
-			case "pgdown", "f":
-				m.viewport.ViewDown()
+			case "enter":
+				m.choosingBranch = false
+				if item, ok := m.branchList.SelectedItem().(branchItem); ok {
+					return m.cherryPick(item.branch.Name)
+				}
 				return m, nil
 			}
+			var cmd tea.Cmd
+			m.branchList, cmd = m.branchList.Update(msg)
+			return m, cmd
+		}
+
+		if m.showDiff {
+			if !m.diff.searching {
+				switch msg.String() {
+				case "q", "ctrl+c", "esc":
+					m.showDiff = false
+					return m, nil
+				}
+			}
+			m.diff, _ = m.diff.update(msg)
+			// The search line in the header comes and goes
+			m = m.layoutDiff()
 			return m, nil
 		}
 
+		m.compareError = ""
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
-		case "enter":
+		case "esc":
+			if len(m.selected) > 0 {
+				m.selected = make(map[string]bool)
+				m = m.updateListItems()
+				return m, nil
+			}
+			return m, tea.Quit
+		case " ":
 			if len(m.list.Items()) > 0 {
-				idx := m.list.Index()
-				commit := m.commits[idx]
-				diff, err := internal.GetCommitDiff(commit.Hash)
-				if err != nil {
-					log.Printf("Error getting diff: %v", err)
-					return m, nil
-				}
-
-				// Apply color highlighting to diff content
-				coloredDiff := m.colorDiff(diff)
-				m.currentDiff = coloredDiff
-				m.viewport.SetContent(coloredDiff)
-				// Force viewport size when entering diff mode
-				// Get current list size and use it as a reference
-				listWidth := m.list.Width()
-				listHeight := m.list.Height()
-				if listWidth > 0 && listHeight > 0 {
-					m.viewport.Width = listWidth
-					m.viewport.Height = listHeight - 10 // Leave room for header and help
+				hash := m.commits[m.list.Index()].Hash
+				if m.selected[hash] {
+					delete(m.selected, hash)
 				} else {
-					// Fallback to reasonable defaults
-					m.viewport.Width = 100
-					m.viewport.Height = 30
+					m.selected[hash] = true
 				}
-				m.showDiff = true
+				m = m.updateListItems()
+			}
+			return m, nil
+		case "p":
+			return m.cherryPick("")
+		case "P":
+			if len(m.list.Items()) == 0 {
+				return m, nil
+			}
+			branches, err := internal.GetBranches()
+			if err != nil {
+				log.Printf("Error getting branches: %v", err)
 				return m, nil
 			}
+			items := make([]list.Item, len(branches))
+			for i, branch := range branches {
+				items[i] = branchItem{branch: branch}
+			}
+			m.branchList = list.New(items, list.NewDefaultDelegate(), m.list.Width(), m.list.Height())
+			m.branchList.Title = "Cherry-pick onto branch"
+			m.branchList.SetShowStatusBar(false)
+			m.branchList.SetFilteringEnabled(false)
+			m.branchList.SetShowHelp(false)
+			m.choosingBranch = true
+			return m, nil
+		case "v":
+			return m.revert()
+		case "r":
+			return m.startReword()
+		case "f":
+			return m.startFixup()
+		case "c":
+			return m.openCompare(), nil
+		case "t":
+			m.showGraph = !m.showGraph
+			m.list.SetDelegate(commitDelegate{
+				DefaultDelegate: list.NewDefaultDelegate(),
+				showGraph:       m.showGraph,
+			})
+			return m, nil
+		case "enter":
+			if len(m.list.Items()) > 0 {
+				return m.openCommit(m.commits[m.list.Index()].Hash), nil
+			}
 		}
 	}
 
+	if m.rewording {
+		// Cursor blink and other textarea messages
+		var cmd tea.Cmd
+		m.rewordInput, cmd = m.rewordInput.Update(msg)
+		return m, cmd
+	}
+
 	if !m.showDiff {
 		var cmd tea.Cmd
 		m.list, cmd = m.list.Update(msg)
@@ -204,105 +490,501 @@ func (m commitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m commitModel) View() string {
-	if m.showDiff {
-		commit := m.commits[m.list.Index()]
-		header := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("170")).
-			Bold(true).
-			Render(fmt.Sprintf("Commit: %s", commit.Hash))
+// openCompare compares two selected commits, a selected commit with the
+// highlighted one, or the highlighted commit with HEAD. The older commit
+// goes on the left.
+func (m commitModel) openCompare() commitModel {
+	if len(m.commits) == 0 {
+		return m
+	}
+
+	var indexes []int
+	for i, commit := range m.commits {
+		if m.selected[commit.Hash] {
+			indexes = append(indexes, i)
+		}
+	}
+	switch len(indexes) {
+	case 0:
+		indexes = []int{0, m.list.Index()}
+	case 1:
+		indexes = append(indexes, m.list.Index())
+	case 2:
+	default:
+		m.compareError = "Select at most two commits to compare"
+		return m
+	}
+	newer, older := min(indexes[0], indexes[1]), max(indexes[0], indexes[1])
+	if newer == older {
+		m.compareError = "Pick a second commit to compare with: select one with space, or highlight another"
+		return m
+	}
+
+	compare, err := newCompareModel(m.commits[older].Hash, m.commits[newer].Hash, m.diff.split, m.width, m.height)
+	if err != nil {
+		m.compareError = "Error comparing commits: " + err.Error()
+		return m
+	}
+	m.compare = compare
+	return m
+}
+
+// actionHashes are the commits an action applies to: the selection if there
+// is one, otherwise the highlighted commit. They are returned newest first.
+func (m commitModel) actionHashes() []string {
+	var hashes []string
+	for _, commit := range m.commits {
+		if m.selected[commit.Hash] {
+			hashes = append(hashes, commit.Hash)
+		}
+	}
+	if len(hashes) == 0 && len(m.list.Items()) > 0 {
+		hashes = append(hashes, m.commits[m.list.Index()].Hash)
+	}
+	return hashes
+}
+
+func (m commitModel) cherryPick(targetBranch string) (tea.Model, tea.Cmd) {
+	hashes := m.actionHashes()
+	if len(hashes) == 0 {
+		return m, nil
+	}
+
+	// Cherry-pick in the order the commits were made
+	for i, j := 0, len(hashes)-1; i < j; i, j = i+1, j-1 {
+		hashes[i], hashes[j] = hashes[j], hashes[i]
+	}
+
+	// A failed checkout of the target is reported in the result panel
+	result, err := internal.CherryPick(hashes, targetBranch)
+	if result == nil {
+		log.Printf("Error cherry-picking: %v", err)
+		return m, nil
+	}
 
-		author := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("243")).
-			Render(fmt.Sprintf("Author: %s", commit.Author))
+	target := targetBranch
+	if target == "" {
+		target = "current branch"
+	}
+	m.resultOperation = "cherry-pick"
+	m.resultTitle = fmt.Sprintf("Cherry-pick %d commit(s) → %s", len(hashes), target)
+	m.resultReturnTo = result.ReturnTo
+	m.resultNote = ""
+	if result.ReturnTo != "" {
+		m.resultNote = fmt.Sprintf("The working tree is now on %s; continuing or aborting checks out %s again", targetBranch, result.ReturnTo)
+	}
+	m.result = result
+	m.showResult = true
+	return m, nil
+}
+
+func (m commitModel) revert() (tea.Model, tea.Cmd) {
+	hashes := m.actionHashes()
+	if len(hashes) == 0 {
+		return m, nil
+	}
+
+	result, err := internal.RevertCommits(hashes)
+	if err != nil {
+		log.Printf("Error reverting: %v", err)
+		return m, nil
+	}
+
+	m.resultOperation = "revert"
+	m.resultTitle = fmt.Sprintf("Revert %d commit(s)", len(hashes))
+	m.result = result
+	m.showResult = true
+	return m, nil
+}
+
+func (m commitModel) startReword() (tea.Model, tea.Cmd) {
+	if len(m.list.Items()) == 0 {
+		return m, nil
+	}
 
-		date := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("243")).
-			Render(fmt.Sprintf("Date: %s", commit.Date.Format("2006-01-02 15:04:05")))
+	hash := m.commits[m.list.Index()].Hash
+	message, err := internal.GetCommitMessage(hash)
+	if err != nil {
+		log.Printf("Error getting commit message: %v", err)
+		return m, nil
+	}
+
+	input := textarea.New()
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.SetWidth(max(20, m.list.Width()-4))
+	input.SetHeight(max(5, m.list.Height()-8))
+	input.SetValue(message)
+	input.Focus()
+
+	m.rewording = true
+	m.rewordHash = hash
+	m.rewordInput = input
+	m.rewordWarning = ""
+	m.rewordOverride = false
+	m.rewordError = ""
+
+	refs, err := internal.ProtectedRefsContaining(hash)
+	if err != nil {
+		log.Printf("Error checking remote branches: %v", err)
+	}
+	if len(refs) > 0 {
+		m.rewordWarning = fmt.Sprintf("This commit is already on protected %s; rewording it rewrites shared history.", strings.Join(refs, ", "))
+	}
 
-		message := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("255")).
-			Render(fmt.Sprintf("Message: %s", commit.Message))
+	return m, textarea.Blink
+}
 
-		help := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Render("↑↓/j k: scroll • pgup/pgdown: page • esc: back • q: quit")
+func (m commitModel) updateReword(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.rewording = false
+		return m, nil
+	case "ctrl+s":
+		message := strings.TrimSpace(m.rewordInput.Value())
+		if message == "" {
+			m.rewordError = "The commit message cannot be empty"
+			return m, nil
+		}
+		// Protected commits need a second save to confirm
+		if m.rewordWarning != "" && !m.rewordOverride {
+			m.rewordOverride = true
+			return m, nil
+		}
+		if err := internal.RewordCommit(m.rewordHash, message); err != nil {
+			m.rewordError = err.Error()
+			return m, nil
+		}
+		return m.refreshCommits()
+	}
 
-		headerContent := lipgloss.JoinVertical(lipgloss.Left, header, author, date, message, "")
+	var cmd tea.Cmd
+	m.rewordInput, cmd = m.rewordInput.Update(msg)
+	return m, cmd
+}
 
-		viewportContent := m.viewport.View()
+func (m commitModel) renderReword() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	lines := []string{
+		titleStyle.Render("Reword commit " + shortHash(m.rewordHash)),
+		"",
+		m.rewordInput.View(),
+		"",
+	}
+	if m.rewordWarning != "" {
+		lines = append(lines, warningStyle.Render(m.rewordWarning))
+		if m.rewordOverride {
+			lines = append(lines, warningStyle.Bold(true).Render("Press ctrl+s again to reword anyway."))
+		}
+	}
+	if m.rewordError != "" {
+		lines = append(lines, errorStyle.Render(m.rewordError))
+	}
+	lines = append(lines, helpStyle.Render("ctrl+s: save • esc: cancel"))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func (m commitModel) updateResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if m.result.HasConflicts {
+			result, err := internal.ContinueSequence(m.resultOperation)
+			if err != nil {
+				log.Printf("Error continuing %s: %v", m.resultOperation, err)
+				return m, nil
+			}
+			m.result = result
+			if result.Success {
+				m = m.returnFromTarget()
+			}
+			return m, nil
+		}
+		return m.refreshCommits()
+	case "m":
+		// Leave the conflicts for the user to resolve outside of smak
+		if m.result.HasConflicts {
+			return m, tea.Quit
+		}
+	case "esc":
+		// Failures such as a refused checkout leave nothing to abort
+		if m.result.HasConflicts || internal.SequenceInProgress() {
+			if err := internal.AbortSequence(m.resultOperation); err != nil {
+				log.Printf("Error aborting %s: %v", m.resultOperation, err)
+			}
+			if m.resultReturnTo != "" {
+				if m = m.returnFromTarget(); m.result.ErrorMessage != "" {
+					return m, nil
+				}
+			}
+		}
+		return m.refreshCommits()
+	}
+	return m, nil
+}
+
+// returnFromTarget checks out the branch a cherry-pick onto another branch
+// started from, reporting in the result panel when that fails.
+func (m commitModel) returnFromTarget() commitModel {
+	if m.resultReturnTo == "" {
+		return m
+	}
+	branch := m.resultReturnTo
+	m.resultReturnTo, m.resultNote = "", ""
+	if err := internal.CheckoutBranch(branch); err != nil {
+		m.result = &internal.MergeResult{ErrorMessage: fmt.Sprintf("checking out %s again failed: %v", branch, err)}
+	}
+	return m
+}
+
+// refreshCommits reloads history after it was changed and closes any panel.
+func (m commitModel) refreshCommits() (tea.Model, tea.Cmd) {
+	commits, err := internal.GetCommits()
+	if err != nil {
+		log.Printf("Error reloading commits: %v", err)
+		return m, tea.Quit
+	}
+
+	newModel := newCommitModel(commits, m.showGraph, m.diff.split)
+	newModel.width = m.width
+	newModel.height = m.height
+	// Preserve window size if we have it
+	if m.list.Width() > 0 && m.list.Height() > 0 {
+		newModel.list.SetSize(m.list.Width(), m.list.Height())
+	}
+
+	return newModel, nil
+}
 
+func (m commitModel) View() string {
+	if m.showResult {
+		var helpText string
+		if m.result.Success {
+			helpText = "enter: continue"
+		} else if m.result.HasConflicts {
+			helpText = "enter: continue after resolving • m: resolve manually (quit) • esc: abort " + m.resultOperation
+		} else {
+			helpText = "enter: continue • esc: abort " + m.resultOperation
+		}
+		operation := "Cherry-pick"
+		if m.resultOperation == "revert" {
+			operation = "Revert"
+		}
+		title := m.resultTitle
+		if m.resultNote != "" {
+			title += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render(m.resultNote)
+		}
+		return renderResultPanel(title, operation, m.result, helpText)
+	}
+
+	if m.rewording {
+		return m.renderReword()
+	}
+
+	if m.fixingUp {
+		return m.renderFixup()
+	}
+
+	if m.compare != nil {
+		return m.compare.View()
+	}
+
+	if m.choosingBranch {
+		helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+		return m.branchList.View() + "\n\n" + helpStyle.Render("↑↓: navigate • enter: cherry-pick onto branch • esc: cancel")
+	}
+
+	if m.showDiff {
 		return lipgloss.JoinVertical(lipgloss.Left,
-			headerContent,
-			viewportContent,
+			m.diffHeader(),
+			m.diff.View(),
 			"",
-			help,
+			m.diffHelp(),
 		)
 	}
 
 	view := m.list.View()
 
+	if m.compareError != "" {
+		view += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render(m.compareError)
+	}
+
 	if m.helpVisible {
 		helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-		help := helpStyle.Render("↑↓: navigate • enter: view commit diff • q: quit")
+		var helpText string
+		if len(m.selected) > 0 {
+			helpText = fmt.Sprintf("↑↓: navigate • space: select (%d) • p/P: cherry-pick here/onto… • v: revert • c: compare • esc: clear • q: quit", len(m.selected))
+		} else {
+			helpText = "↑↓: navigate • enter: diff • space: select • p/P: cherry-pick here/onto… • v: revert • r: reword • f: fixup • c: compare with HEAD • t: graph • q: quit"
+		}
+		help := helpStyle.Render(helpText)
 		view += "\n\n" + help
 	}
 
 	return view
 }
 
-func (m commitModel) colorDiff(diff string) string {
-	lines := strings.Split(diff, "\n")
-	var coloredLines []string
+// openCommit loads a commit's details and diff and switches to the diff view.
+func (m commitModel) openCommit(hash string) commitModel {
+	details, err := internal.GetCommitDetails(hash)
+	if err != nil {
+		log.Printf("Error getting commit details: %v", err)
+		return m
+	}
+	diff, err := internal.GetCommitDiff(hash)
+	if err != nil {
+		log.Printf("Error getting diff: %v", err)
+		return m
+	}
+	files, err := internal.GetCommitFiles(hash)
+	if err != nil {
+		log.Printf("Error getting changed files: %v", err)
+	}
 
-	for _, line := range lines {
-		if len(line) == 0 {
-			coloredLines = append(coloredLines, line)
-			continue
+	m.details = details
+	m.diff = m.diff.setDiff(diff, files)
+	m.showDiff = true
+	return m.layoutDiff()
+}
+
+// maxBodyLines keeps long commit messages from pushing the diff off screen.
+const maxBodyLines = 8
+
+func (m commitModel) diffHeader() string {
+	commit := m.details
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Width(11)
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+	field := func(label, value string) string {
+		return labelStyle.Render(label) + valueStyle.Render(value)
+	}
+
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("170")).
+		Bold(true).
+		Render(fmt.Sprintf("Commit: %s", commit.Hash))
+	if refs := renderRefs(commit.Refs); refs != "" {
+		header += " " + refs
+	}
+
+	lines := []string{header}
+
+	if len(commit.Parents) > 0 {
+		parents := make([]string, len(commit.Parents))
+		for i, parent := range commit.Parents {
+			parents[i] = shortHash(parent)
 		}
+		label := "Parent:"
+		if len(parents) > 1 {
+			label = "Parents:"
+		}
+		lines = append(lines, field(label, strings.Join(parents, " ")))
+	}
 
-		switch line[0] {
-		case '+':
-			// Green background for added lines
-			if line == "+++" || strings.HasPrefix(line, "+++ ") {
-				// File header - use normal green text, no background
-				style := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
-				coloredLines = append(coloredLines, style.Render(line))
-			} else {
-				// Added line - green background with black text
-				style := lipgloss.NewStyle().Background(lipgloss.Color("46")).Foreground(lipgloss.Color("0"))
-				coloredLines = append(coloredLines, style.Render(line))
-			}
-		case '-':
-			// Red background for deleted lines
-			if line == "---" || strings.HasPrefix(line, "--- ") {
-				// File header - use normal red text, no background
-				style := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
-				coloredLines = append(coloredLines, style.Render(line))
-			} else {
-				// Deleted line - red background with black text
-				style := lipgloss.NewStyle().Background(lipgloss.Color("196")).Foreground(lipgloss.Color("0"))
-				coloredLines = append(coloredLines, style.Render(line))
-			}
-		case '@':
-			// Hunk headers (@@) - blue
-			if strings.HasPrefix(line, "@@") {
-				style := lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true)
-				coloredLines = append(coloredLines, style.Render(line))
-			} else {
-				coloredLines = append(coloredLines, line)
-			}
-		default:
-			// Context lines and other content - no special styling
-			coloredLines = append(coloredLines, line)
+	lines = append(lines, field("Author:", fmt.Sprintf("%s <%s>  %s", commit.Author, commit.AuthorEmail, commit.Date.Format("2006-01-02 15:04:05"))))
+	if commit.Committer != commit.Author || commit.CommitterEmail != commit.AuthorEmail || !commit.CommitDate.Equal(commit.Date) {
+		lines = append(lines, field("Committer:", fmt.Sprintf("%s <%s>  %s", commit.Committer, commit.CommitterEmail, commit.CommitDate.Format("2006-01-02 15:04:05"))))
+	}
+
+	if commit.Signature.Signed() {
+		lines = append(lines, labelStyle.Render("Signature:")+renderSignature(commit.Signature))
+	}
+
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Bold(true).Render(commit.Message))
+
+	if commit.Body != "" {
+		bodyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).PaddingLeft(2)
+		body := strings.Split(commit.Body, "\n")
+		if len(body) > maxBodyLines {
+			hidden := len(body) - maxBodyLines
+			body = append(body[:maxBodyLines], lipgloss.NewStyle().Foreground(lipgloss.Color("241")).
+				Render(fmt.Sprintf("… %d more lines", hidden)))
+		}
+		lines = append(lines, bodyStyle.Render(strings.Join(body, "\n")))
+	}
+
+	if len(commit.Trailers) > 0 {
+		keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("33"))
+		for _, trailer := range commit.Trailers {
+			lines = append(lines, "  "+keyStyle.Render(trailer.Key+":")+" "+valueStyle.Render(trailer.Value))
 		}
 	}
 
-	return strings.Join(coloredLines, "\n")
+	lines = append(lines, "", m.diff.stat())
+	if status := m.diff.searchStatus(); status != "" {
+		lines = append(lines, status)
+	}
+	lines = append(lines, "")
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func renderSignature(signature internal.Signature) string {
+	var text string
+	var color lipgloss.Color
+	switch signature.Status {
+	case "G":
+		text, color = "✓ Good signature", "46"
+	case "U":
+		text, color = "✓ Good signature (unknown validity)", "226"
+	case "X":
+		text, color = "! Good signature, expired", "208"
+	case "Y":
+		text, color = "! Good signature, expired key", "208"
+	case "R":
+		text, color = "✗ Signed with a revoked key", "196"
+	case "B":
+		text, color = "✗ Bad signature", "196"
+	default:
+		text, color = "? Signature cannot be checked", "243"
+	}
+
+	if signature.Signer != "" {
+		text += " from " + signature.Signer
+	}
+	if signature.Key != "" {
+		text += " (" + signature.Key + ")"
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(text)
+}
+
+func (m commitModel) diffHelp() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	if m.width > 0 {
+		style = style.Width(m.width)
+	}
+	return style.Render(m.diff.helpText() + " • esc: back • q: quit")
+}
+
+// layoutDiff sizes the diff view to whatever is left after the header and help.
+func (m commitModel) layoutDiff() commitModel {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		// Fallback to reasonable defaults until the first WindowSizeMsg
+		width, height = 100, 40
+	}
+
+	helpHeight := lipgloss.Height(m.diffHelp()) + 1
+	m.diff = m.diff.setSize(width, height-lipgloss.Height(m.diffHeader())-helpHeight)
+	return m
 }
 
 func init() {
-	commitAmendCmd.Flags().BoolP("push", "p", false, "Push the amended commit to origin with force")
+	commitsCmd.Flags().BoolP("graph", "g", false, "Show the commit graph next to the commit list")
+	commitsCmd.Flags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
+	commitAmendCmd.Flags().BoolP("push", "p", false, "Push the amended commit to its upstream with --force-with-lease")
+	commitAmendCmd.Flags().BoolP("interactive", "i", false, "Choose the files and hunks to amend")
+	commitAmendCmd.Flags().Bool("staged-only", false, "Amend only what is already staged")
+	commitAmendCmd.Flags().StringP("message", "m", "", "Replace the commit message")
+	commitAmendCmd.Flags().BoolP("edit", "e", false, "Edit the commit message in your editor")
 	commitsCmd.AddCommand(commitAmendCmd)
+	fixupCmd.Flags().BoolP("all", "a", false, "Stage all changes before committing the fixup")
+	fixupCmd.Flags().BoolP("interactive", "i", false, "Choose the files and hunks to commit as the fixup")
+	fixupCmd.Flags().Bool("autosquash", false, "Fold the fixup into its target right away with rebase --autosquash")
+	commitsCmd.AddCommand(rebaseCmd)
+	commitsCmd.AddCommand(fixupCmd)
 	rootCmd.AddCommand(commitsCmd)
 }
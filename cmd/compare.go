@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+// compareModel compares two branches or commits: the commits unique to each
+// side, and the diff between them. It is opened from the branch and commit
+// views, which forward messages to it until it is closed.
+type compareModel struct {
+	comparison *internal.Comparison
+	// threeDot diffs right against the merge base instead of against left
+	threeDot bool
+	showDiff bool
+	// focus is the list (0 left, 1 right) that keys move in
+	focus  int
+	lists  [2]list.Model
+	diff   diffView
+	detail commitModel
+	width  int
+	height int
+
+	closed  bool
+	message string
+}
+
+func newCompareModel(left, right string, splitDiff bool, width, height int) (*compareModel, error) {
+	comparison, err := internal.CompareRefs(left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &compareModel{
+		comparison: comparison,
+		threeDot:   true,
+		diff:       newDiffView(splitDiff),
+		detail:     newCommitModel(nil, false, splitDiff),
+		width:      width,
+		height:     height,
+	}
+	for side, commits := range [2][]internal.Commit{comparison.LeftOnly, comparison.RightOnly} {
+		items := make([]list.Item, len(commits))
+		for i, commit := range commits {
+			items[i] = commitItem{commit: commit}
+		}
+		l := list.New(items, commitDelegate{DefaultDelegate: list.NewDefaultDelegate()}, 0, 0)
+		l.SetShowStatusBar(false)
+		l.SetFilteringEnabled(false)
+		l.SetShowHelp(false)
+		m.lists[side] = l
+	}
+	m.lists[0].Title = fmt.Sprintf("Only on %s (%d)", refLabel(left), len(comparison.LeftOnly))
+	m.lists[1].Title = fmt.Sprintf("Only on %s (%d)", refLabel(right), len(comparison.RightOnly))
+	if len(comparison.LeftOnly) == 0 && len(comparison.RightOnly) > 0 {
+		m.focus = 1
+	}
+
+	updated := m.loadDiff()
+	if width > 0 && height > 0 {
+		detail, _ := updated.detail.Update(tea.WindowSizeMsg{Width: width, Height: height})
+		updated.detail = detail.(commitModel)
+	}
+	return &updated, nil
+}
+
+// refLabel shortens commit hashes and leaves branch names alone.
+func refLabel(ref string) string {
+	if len(ref) == 40 && strings.Trim(ref, "0123456789abcdef") == "" {
+		return shortHash(ref)
+	}
+	return ref
+}
+
+func (m compareModel) loadDiff() compareModel {
+	raw, err := internal.GetCompareDiff(m.comparison.Left, m.comparison.Right, m.threeDot)
+	if err != nil {
+		m.message = "Error getting diff: " + err.Error()
+		raw = ""
+	}
+	m.diff = m.diff.setDiff(raw, nil)
+	return m.layout()
+}
+
+func (m compareModel) layout() compareModel {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = 100, 40
+	}
+
+	bodyHeight := height - lipgloss.Height(m.header()) - 3
+	if m.showDiff {
+		m.diff = m.diff.setSize(width, bodyHeight)
+	}
+	listWidth := width / 2
+	m.lists[0].SetSize(listWidth-1, bodyHeight)
+	m.lists[1].SetSize(width-listWidth-1, bodyHeight)
+	return m
+}
+
+func (m compareModel) update(msg tea.Msg) (compareModel, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width, m.height = size.Width, size.Height
+		updated, _ := m.detail.Update(msg)
+		m.detail = updated.(commitModel)
+		return m.layout(), nil
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.detail.showDiff {
+		updated, cmd := m.detail.Update(msg)
+		m.detail = updated.(commitModel)
+		return m, cmd
+	}
+
+	m.message = ""
+	if m.showDiff {
+		if !m.diff.searching {
+			switch key.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "q", "esc":
+				m.showDiff = false
+				return m.layout(), nil
+			case "m":
+				m.threeDot = !m.threeDot
+				return m.loadDiff(), nil
+			}
+		}
+		m.diff, _ = m.diff.update(key)
+		// The search line in the header comes and goes
+		return m.layout(), nil
+	}
+
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc":
+		m.closed = true
+		return m, nil
+	case "d":
+		m.showDiff = true
+		return m.layout(), nil
+	case "m":
+		m.threeDot = !m.threeDot
+		return m.loadDiff(), nil
+	case "left", "h":
+		m.focus = 0
+		return m, nil
+	case "right", "l":
+		m.focus = 1
+		return m, nil
+	case "tab":
+		m.focus = 1 - m.focus
+		return m, nil
+	case "enter":
+		if item, ok := m.lists[m.focus].SelectedItem().(commitItem); ok {
+			m.detail = m.detail.openCommit(item.commit.Hash)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.lists[m.focus], cmd = m.lists[m.focus].Update(msg)
+	return m, cmd
+}
+
+// header names the two sides, their merge base and the diff mode.
+func (m compareModel) header() string {
+	c := m.comparison
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+
+	left, right := refLabel(c.Left), refLabel(c.Right)
+	lines := []string{titleStyle.Render("Compare " + left + " ↔ " + right)}
+
+	base := "no common history"
+	if c.MergeBase != "" {
+		base = "forked at " + shortHash(c.MergeBase)
+	}
+	lines = append(lines, dimStyle.Render(fmt.Sprintf("%s • %d commits only on %s, %d only on %s", base, len(c.LeftOnly), left, len(c.RightOnly), right)))
+
+	mode := fmt.Sprintf("Diff %s..%s: every difference between the two", left, right)
+	if m.threeDot {
+		mode = fmt.Sprintf("Diff %s...%s: what %s changed since the fork", left, right, right)
+	}
+	lines = append(lines, dimStyle.Render(mode)+"  "+m.diff.stat())
+	if m.showDiff {
+		if status := m.diff.searchStatus(); status != "" {
+			lines = append(lines, status)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m compareModel) View() string {
+	if m.detail.showDiff {
+		return m.detail.View()
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	if m.width > 0 {
+		helpStyle = helpStyle.Width(m.width)
+	}
+
+	var body, help string
+	if m.showDiff {
+		body = m.diff.View()
+		help = m.diff.helpText() + " • m: two-dot/three-dot • esc: commits"
+	} else {
+		panes := make([]string, 2)
+		for side := range m.lists {
+			style := lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, false, false, true).
+				BorderForeground(lipgloss.Color("238")).
+				Width(m.lists[side].Width()).
+				Height(m.lists[side].Height())
+			if side == m.focus {
+				style = style.BorderForeground(lipgloss.Color("170"))
+			}
+			l := m.lists[side]
+			pane := l.View()
+			if len(l.Items()) == 0 {
+				pane = l.Styles.TitleBar.Render(l.Styles.Title.Render(l.Title)) + "\n\n" +
+					lipgloss.NewStyle().Foreground(lipgloss.Color("243")).PaddingLeft(2).Render("Nothing here")
+			}
+			panes[side] = style.Render(pane)
+		}
+		body = lipgloss.JoinHorizontal(lipgloss.Top, panes...)
+		help = "↑↓: navigate • ←→/tab: switch side • enter: commit diff • d: diff • m: two-dot/three-dot • esc: back"
+	}
+
+	view := m.header() + "\n" + body
+	if m.message != "" {
+		view += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	}
+	return view + "\n" + helpStyle.Render(help)
+}
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var composeCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Compose a Conventional Commits message and commit the staged changes",
+	Long: `Guided form for a Conventional Commits message: type, scope (suggested from
+history), breaking change flag, subject, body and issue trailers. The message is
+validated as you type and the staged changes are committed when it is valid.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// With -a the changes are staged only once the message is confirmed, so
+		// cancelling leaves the index as it was
+		all, _ := cmd.Flags().GetBool("all")
+		var staged bool
+		if all {
+			entries, err := internal.GetStatus()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			staged = len(entries) > 0
+		} else {
+			var err error
+			if staged, err = internal.HasStagedChanges(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+		if !staged && !internal.DryRun {
+			fmt.Println("Nothing staged to commit (use -a to stage all changes, or smak s to pick them)")
+			return
+		}
+
+		// History is only used for scope suggestions, so a fresh repository is fine
+		commits, _ := internal.GetCommits()
+
+		p := tea.NewProgram(newComposeModel(internal.SuggestScopes(commits)), tea.WithAltScreen())
+		final, err := p.Run()
+		if err != nil {
+			fmt.Printf("Error running program: %v\n", err)
+			return
+		}
+
+		m := final.(composeModel)
+		if !m.confirmed {
+			fmt.Println("Commit cancelled")
+			return
+		}
+
+		if all {
+			if err := internal.StageFile(":/"); err != nil {
+				fmt.Printf("Error staging changes: %v\n", err)
+				return
+			}
+		}
+
+		commit := m.commit()
+		if err := internal.CreateCommit(commit.Message()); err != nil {
+			fmt.Printf("Error committing: %v\n", err)
+			return
+		}
+		fmt.Printf("Committed: %s\n", commit.Header())
+	},
+}
+
+type composeField int
+
+const (
+	fieldType composeField = iota
+	fieldScope
+	fieldBreaking
+	fieldBreakingChange
+	fieldSubject
+	fieldBody
+	fieldCloses
+	fieldRefs
+	fieldCount
+)
+
+var composeLabels = map[composeField]string{
+	fieldType:           "Type",
+	fieldScope:          "Scope",
+	fieldBreaking:       "Breaking",
+	fieldBreakingChange: "Change",
+	fieldSubject:        "Subject",
+	fieldBody:           "Body",
+	fieldCloses:         "Closes",
+	fieldRefs:           "Refs",
+}
+
+type composeModel struct {
+	typeIdx        int
+	scope          textinput.Model
+	breaking       bool
+	breakingChange textinput.Model
+	subject        textinput.Model
+	body           textarea.Model
+	closes         textinput.Model
+	refs           textinput.Model
+
+	scopes    []string
+	focus     composeField
+	confirmed bool
+	// showProblems is set after the first attempt to commit an invalid message
+	showProblems bool
+	width        int
+	height       int
+}
+
+func newComposeModel(scopes []string) composeModel {
+	newInput := func(placeholder string) textinput.Model {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = placeholder
+		return input
+	}
+
+	scope := newInput("optional")
+	if len(scopes) > 0 {
+		scope.Placeholder = "optional, e.g. " + scopes[0]
+	}
+	scope.ShowSuggestions = true
+	scope.SetSuggestions(scopes)
+	scope.KeyMap.AcceptSuggestion = key.NewBinding(key.WithKeys("right"))
+
+	body := textarea.New()
+	body.ShowLineNumbers = false
+	body.CharLimit = 0
+	body.Placeholder = "Optional: explain what and why"
+	body.SetHeight(5)
+
+	return composeModel{
+		scope:          scope,
+		breakingChange: newInput("what breaks and how to migrate"),
+		subject:        newInput("short imperative summary"),
+		body:           body,
+		closes:         newInput("issues this fixes, e.g. #123, PROJ-45"),
+		refs:           newInput("related issues"),
+		scopes:         scopes,
+	}
+}
+
+// commit assembles the message from the form.
+func (m composeModel) commit() internal.ConventionalCommit {
+	return internal.ConventionalCommit{
+		Type:           internal.ConventionalTypes[m.typeIdx],
+		Scope:          strings.TrimSpace(m.scope.Value()),
+		Breaking:       m.breaking,
+		BreakingChange: m.breakingChange.Value(),
+		Subject:        m.subject.Value(),
+		Body:           strings.TrimRight(m.body.Value(), "\n"),
+		Closes:         internal.ParseIssues(m.closes.Value()),
+		Refs:           internal.ParseIssues(m.refs.Value()),
+	}
+}
+
+// setFocus moves the cursor to a field, skipping the breaking change note
+// when the commit is not breaking.
+func (m composeModel) setFocus(field composeField, step int) composeModel {
+	field = (field + fieldCount) % fieldCount
+	if field == fieldBreakingChange && !m.breaking {
+		field = (field + composeField(step) + fieldCount) % fieldCount
+	}
+	m.focus = field
+
+	m.scope.Blur()
+	m.breakingChange.Blur()
+	m.subject.Blur()
+	m.body.Blur()
+	m.closes.Blur()
+	m.refs.Blur()
+
+	switch field {
+	case fieldScope:
+		m.scope.Focus()
+	case fieldBreakingChange:
+		m.breakingChange.Focus()
+	case fieldSubject:
+		m.subject.Focus()
+	case fieldBody:
+		m.body.Focus()
+	case fieldCloses:
+		m.closes.Focus()
+	case fieldRefs:
+		m.refs.Focus()
+	}
+	return m
+}
+
+func (m composeModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m composeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		inputWidth := max(20, min(80, m.width-16))
+		m.body.SetWidth(inputWidth)
+		for _, input := range []*textinput.Model{&m.scope, &m.breakingChange, &m.subject, &m.closes, &m.refs} {
+			input.Width = inputWidth
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, tea.Quit
+		case "ctrl+s":
+			if len(m.commit().Validate()) > 0 {
+				m.showProblems = true
+				return m, nil
+			}
+			m.confirmed = true
+			return m, tea.Quit
+		case "tab", "down":
+			if msg.String() == "tab" || m.focus != fieldBody {
+				return m.setFocus(m.focus+1, 1), nil
+			}
+		case "shift+tab", "up":
+			if msg.String() == "shift+tab" || m.focus != fieldBody {
+				return m.setFocus(m.focus-1, -1), nil
+			}
+		case "enter":
+			if m.focus != fieldBody {
+				return m.setFocus(m.focus+1, 1), nil
+			}
+		}
+
+		switch m.focus {
+		case fieldType:
+			switch msg.String() {
+			case "left", "h":
+				m.typeIdx = (m.typeIdx + len(internal.ConventionalTypes) - 1) % len(internal.ConventionalTypes)
+			case "right", "l":
+				m.typeIdx = (m.typeIdx + 1) % len(internal.ConventionalTypes)
+			}
+			return m, nil
+		case fieldBreaking:
+			switch msg.String() {
+			case " ", "y", "n":
+				m.breaking = msg.String() == "y" || (msg.String() == " " && !m.breaking)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch m.focus {
+	case fieldScope:
+		m.scope, cmd = m.scope.Update(msg)
+	case fieldBreakingChange:
+		m.breakingChange, cmd = m.breakingChange.Update(msg)
+	case fieldSubject:
+		m.subject, cmd = m.subject.Update(msg)
+	case fieldBody:
+		m.body, cmd = m.body.Update(msg)
+	case fieldCloses:
+		m.closes, cmd = m.closes.Update(msg)
+	case fieldRefs:
+		m.refs, cmd = m.refs.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m composeModel) View() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Width(10)
+	focusedLabelStyle := labelStyle.Foreground(lipgloss.Color("170")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	field := func(f composeField, value string) string {
+		style := labelStyle
+		if m.focus == f {
+			style = focusedLabelStyle
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, style.Render(composeLabels[f]), value)
+	}
+
+	var types []string
+	for i, t := range internal.ConventionalTypes {
+		if i == m.typeIdx {
+			types = append(types, lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true).Render("["+t+"]"))
+		} else {
+			types = append(types, dimStyle.Render(t))
+		}
+	}
+
+	commit := m.commit()
+	lines := []string{
+		titleStyle.Render("New commit"),
+		"",
+		field(fieldType, strings.Join(types, " ")),
+		field(fieldScope, m.scope.View()),
+	}
+	if m.focus == fieldScope && len(m.scopes) > 0 {
+		lines = append(lines, labelStyle.Render("")+dimStyle.Render("used before: "+strings.Join(m.scopes[:min(len(m.scopes), 8)], ", ")+" (→ completes)"))
+	}
+
+	breaking := "[ ] no"
+	if m.breaking {
+		breaking = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render("[x] yes")
+	}
+	lines = append(lines, field(fieldBreaking, breaking))
+	if m.breaking {
+		lines = append(lines, field(fieldBreakingChange, m.breakingChange.View()))
+	}
+
+	headerLength := len([]rune(commit.Header()))
+	counter := dimStyle.Render(fmt.Sprintf(" %d/%d", headerLength, internal.MaxHeaderLength))
+	if headerLength > internal.MaxHeaderLength {
+		counter = errorStyle.Render(fmt.Sprintf(" %d/%d", headerLength, internal.MaxHeaderLength))
+	}
+	lines = append(lines,
+		field(fieldSubject, m.subject.View()+counter),
+		field(fieldBody, m.body.View()),
+		field(fieldCloses, m.closes.View()),
+		field(fieldRefs, m.refs.View()),
+		"",
+	)
+
+	preview := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("238")).
+		Padding(0, 1).
+		Render(commit.Message())
+	lines = append(lines, dimStyle.Render("Preview"), preview)
+
+	if problems := commit.Validate(); len(problems) > 0 && m.showProblems {
+		for _, problem := range problems {
+			lines = append(lines, errorStyle.Render("✗ "+problem))
+		}
+	}
+
+	lines = append(lines, "", dimStyle.Render("tab/shift+tab: next/previous field • ←/→: change type • space: toggle breaking • ctrl+s: commit • esc: cancel"))
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func init() {
+	composeCmd.Flags().BoolP("all", "a", false, "Stage all changes when the commit is confirmed")
+	rootCmd.AddCommand(composeCmd)
+}
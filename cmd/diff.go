@@ -0,0 +1,759 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+// diffView shows a patch with a pane of changed files next to it and lets the
+// user jump between files and hunks or collapse files they are not interested in.
+type diffView struct {
+	viewport    viewport.Model
+	preamble    []string
+	files       []internal.FileChange
+	diffs       []internal.FileDiff
+	collapsed   map[int]bool
+	fileOffsets []int
+	hunkOffsets []int
+	selected    int
+	split       bool
+	width       int
+	height      int
+	// hideFiles drops the file pane, for views that list files themselves
+	hideFiles bool
+
+	lines       []string
+	searchInput textinput.Model
+	searching   bool
+	query       string
+	matches     []searchMatch
+	matchIdx    int
+}
+
+// searchMatch is one occurrence of the search query, as a byte range in the
+// plain text of a content line.
+type searchMatch struct {
+	line  int
+	start int
+	end   int
+}
+
+func newDiffView(split bool) diffView {
+	vp := viewport.New(0, 0)
+	vp.Style = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
+
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "search diff"
+
+	return diffView{
+		viewport:    vp,
+		collapsed:   make(map[int]bool),
+		split:       split,
+		searchInput: input,
+	}
+}
+
+// splitDiffDefault decides whether diffs open side by side: the flag wins,
+// otherwise the smak.diffStyle git config value is used.
+func splitDiffDefault(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("split") {
+		split, _ := cmd.Flags().GetBool("split")
+		return split
+	}
+	return internal.GetConfig("smak.diffStyle") == "split"
+}
+
+// setDiff loads a raw patch. files may be nil, in which case the file list is
+// derived from the patch itself.
+func (d diffView) setDiff(raw string, files []internal.FileChange) diffView {
+	preamble, fileDiffs := internal.SplitDiff(raw)
+	d.preamble = preamble
+	d.collapsed = make(map[int]bool)
+	d.query = ""
+	d.matches = nil
+
+	// Line the per-file patches up with the file list
+	used := make(map[int]bool)
+	d.files = nil
+	d.diffs = nil
+	for _, file := range files {
+		fileDiff := internal.FileDiff{Path: file.Path}
+		for i, candidate := range fileDiffs {
+			if !used[i] && (candidate.Path == file.Path || (file.OldPath != "" && candidate.Path == file.OldPath)) {
+				fileDiff = candidate
+				used[i] = true
+				break
+			}
+		}
+		d.files = append(d.files, file)
+		d.diffs = append(d.diffs, fileDiff)
+	}
+	for i, fileDiff := range fileDiffs {
+		if used[i] {
+			continue
+		}
+		d.files = append(d.files, fileChangeFromDiff(fileDiff))
+		d.diffs = append(d.diffs, fileDiff)
+	}
+
+	d = d.render()
+	d.viewport.GotoTop()
+	d.selected = 0
+	return d
+}
+
+func fileChangeFromDiff(fileDiff internal.FileDiff) internal.FileChange {
+	change := internal.FileChange{Path: fileDiff.Path, Status: "M"}
+	for _, line := range fileDiff.Lines {
+		switch {
+		case strings.HasPrefix(line, "new file"):
+			change.Status = "A"
+		case strings.HasPrefix(line, "deleted file"):
+			change.Status = "D"
+		case strings.HasPrefix(line, "rename from "):
+			change.Status = "R"
+			change.OldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "Binary files"):
+			change.Binary = true
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			change.Additions++
+		case strings.HasPrefix(line, "-"):
+			change.Deletions++
+		}
+	}
+	return change
+}
+
+// render rebuilds the viewport content, honouring collapsed files, and
+// records where every file and hunk starts.
+func (d diffView) render() diffView {
+	var lines []string
+	d.fileOffsets = make([]int, len(d.diffs))
+	d.hunkOffsets = nil
+
+	for _, line := range d.preamble {
+		lines = append(lines, colorDiff(line))
+	}
+	for i, fileDiff := range d.diffs {
+		d.fileOffsets[i] = len(lines)
+		if len(fileDiff.Lines) == 0 {
+			continue
+		}
+
+		if d.collapsed[i] {
+			marker := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).
+				Render(fmt.Sprintf("▸ %s (%d lines hidden)", d.files[i].Path, len(fileDiff.Lines)-1))
+			lines = append(lines, colorDiff(fileDiff.Lines[0]), marker)
+			continue
+		}
+
+		lang := internal.LanguageForPath(d.files[i].Path)
+		for _, line := range fileDiff.Header() {
+			lines = append(lines, colorDiff(line))
+		}
+		for _, hunk := range fileDiff.Hunks() {
+			d.hunkOffsets = append(d.hunkOffsets, len(lines))
+			lines = append(lines, colorDiff(hunk.Header))
+			if d.splitActive() {
+				lines = append(lines, d.renderSplitHunk(hunk, lang)...)
+			} else {
+				lines = append(lines, renderUnifiedHunk(hunk, lang)...)
+			}
+		}
+	}
+
+	d.lines = lines
+	return d.applySearch()
+}
+
+func (d diffView) setSize(width, height int) diffView {
+	resized := d.viewport.Width != width-d.filePaneWidth()
+	d.width = width
+	d.height = height
+
+	d.viewport.Width = width - d.filePaneWidth()
+	d.viewport.Height = height
+	if resized && d.split {
+		// Split columns depend on the width
+		d = d.render()
+	}
+	return d
+}
+
+// splitActive reports whether the side-by-side layout is used; narrow
+// viewports fall back to the unified diff.
+func (d diffView) splitActive() bool {
+	return d.split && d.contentWidth() >= 80
+}
+
+func (d diffView) contentWidth() int {
+	return d.viewport.Width - d.viewport.Style.GetHorizontalFrameSize()
+}
+
+// renderUnifiedHunk renders hunk lines with their +/- prefix in front of the
+// highlighted code.
+func renderUnifiedHunk(hunk internal.Hunk, lang *internal.Language) []string {
+	bodies := renderHunkBodies(hunk, lang)
+	rows := make([]string, len(hunk.Lines))
+	for i, line := range hunk.Lines {
+		switch line.Kind {
+		case '+':
+			rows[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("+") + bodies[i]
+		case '-':
+			rows[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("-") + bodies[i]
+		case '\\':
+			rows[i] = bodies[i]
+		default:
+			rows[i] = " " + bodies[i]
+		}
+	}
+	return rows
+}
+
+// renderSplitHunk lays a hunk out in two columns: old on the left, new on the
+// right. Runs of removed lines are paired with the added lines that follow them.
+func (d diffView) renderSplitHunk(hunk internal.Hunk, lang *internal.Language) []string {
+	columnWidth := (d.contentWidth() - 1) / 2
+	separator := lipgloss.NewStyle().Foreground(lipgloss.Color("238")).Render("│")
+	bodies := renderHunkBodies(hunk, lang)
+
+	var rows []string
+	addRow := func(left, right int) {
+		rows = append(rows, renderSplitCell(hunk, bodies, left, columnWidth, false)+separator+renderSplitCell(hunk, bodies, right, columnWidth, true))
+	}
+
+	lines := hunk.Lines
+	for i := 0; i < len(lines); {
+		switch lines[i].Kind {
+		case '-', '+':
+			removed, added := changeRun(lines, i)
+			for j := 0; j < max(len(removed), len(added)); j++ {
+				left, right := -1, -1
+				if j < len(removed) {
+					left = removed[j]
+				}
+				if j < len(added) {
+					right = added[j]
+				}
+				addRow(left, right)
+			}
+			i += len(removed) + len(added)
+		case '\\':
+			i++
+		default:
+			addRow(i, i)
+			i++
+		}
+	}
+
+	return rows
+}
+
+// changeRun returns the indexes of the removed lines starting at i and of the
+// added lines directly following them.
+func changeRun(lines []internal.DiffLine, i int) ([]int, []int) {
+	var removed, added []int
+	for i < len(lines) && lines[i].Kind == '-' {
+		removed = append(removed, i)
+		i++
+	}
+	for i < len(lines) && lines[i].Kind == '+' {
+		added = append(added, i)
+		i++
+	}
+	return removed, added
+}
+
+func renderSplitCell(hunk internal.Hunk, bodies []string, idx, width int, newSide bool) string {
+	if idx == -1 {
+		return lipgloss.NewStyle().Background(lipgloss.Color("236")).Render(strings.Repeat(" ", width))
+	}
+
+	line := hunk.Lines[idx]
+	num := line.OldNum
+	if newSide {
+		num = line.NewNum
+	}
+	gutter := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(fmt.Sprintf("%4d ", num))
+
+	textWidth := max(0, width-5)
+	text := lipgloss.NewStyle().MaxWidth(textWidth).Render(bodies[idx])
+	padding := strings.Repeat(" ", max(0, textWidth-lipgloss.Width(text)))
+	return gutter + text + diffLineStyle(line.Kind, false).Render(padding)
+}
+
+// renderHunkBodies syntax-highlights every line of a hunk (without its +/-
+// prefix) and marks the words that changed between paired -/+ lines.
+func renderHunkBodies(hunk internal.Hunk, lang *internal.Language) []string {
+	lines := hunk.Lines
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = strings.ReplaceAll(line.Text, "\t", "    ")
+	}
+
+	changed := make([][]internal.Range, len(lines))
+	for i := 0; i < len(lines); {
+		if lines[i].Kind != '-' && lines[i].Kind != '+' {
+			i++
+			continue
+		}
+		removed, added := changeRun(lines, i)
+		for j := 0; j < min(len(removed), len(added)); j++ {
+			changed[removed[j]], changed[added[j]] = internal.WordDiff(texts[removed[j]], texts[added[j]])
+		}
+		i += len(removed) + len(added)
+	}
+
+	bodies := make([]string, len(lines))
+	for i, line := range lines {
+		if line.Kind == '\\' {
+			bodies[i] = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(texts[i])
+			continue
+		}
+		bodies[i] = renderCode(texts[i], lang, line.Kind, changed[i])
+	}
+	return bodies
+}
+
+// renderCode colours a line of code by token, over a subtle background for
+// added/removed lines and a stronger one for the changed words.
+func renderCode(text string, lang *internal.Language, kind byte, changed []internal.Range) string {
+	var b strings.Builder
+	offset := 0
+	for _, token := range internal.Highlight(lang, text) {
+		start := offset
+		end := offset + len(token.Text)
+		offset = end
+
+		// Split the token wherever a changed range begins or ends
+		for start < end {
+			highlighted, next := false, end
+			for _, r := range changed {
+				if r.Start <= start && start < r.End {
+					highlighted, next = true, min(end, r.End)
+					break
+				}
+				if r.Start > start && r.Start < next {
+					next = r.Start
+				}
+			}
+
+			style := diffLineStyle(kind, highlighted)
+			switch token.Kind {
+			case internal.TokenKeyword:
+				style = style.Foreground(lipgloss.Color("204"))
+			case internal.TokenString:
+				style = style.Foreground(lipgloss.Color("114"))
+			case internal.TokenComment:
+				style = style.Foreground(lipgloss.Color("244")).Italic(true)
+			case internal.TokenNumber:
+				style = style.Foreground(lipgloss.Color("179"))
+			}
+			b.WriteString(style.Render(text[start:next]))
+			start = next
+		}
+	}
+	return b.String()
+}
+
+func diffLineStyle(kind byte, highlighted bool) lipgloss.Style {
+	switch {
+	case kind == '+' && highlighted:
+		return lipgloss.NewStyle().Background(lipgloss.Color("28"))
+	case kind == '+':
+		return lipgloss.NewStyle().Background(lipgloss.Color("22"))
+	case kind == '-' && highlighted:
+		return lipgloss.NewStyle().Background(lipgloss.Color("88"))
+	case kind == '-':
+		return lipgloss.NewStyle().Background(lipgloss.Color("52"))
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+func (d diffView) filePaneWidth() int {
+	if d.hideFiles || len(d.files) == 0 || d.width < 60 {
+		return 0
+	}
+	return min(40, d.width/3)
+}
+
+// fileAtOffset is the file shown at the top of the viewport.
+func (d diffView) fileAtOffset() int {
+	current := 0
+	for i, offset := range d.fileOffsets {
+		if offset <= d.viewport.YOffset {
+			current = i
+		}
+	}
+	return current
+}
+
+func (d diffView) selectFile(idx int) diffView {
+	if idx < 0 || idx >= len(d.fileOffsets) {
+		return d
+	}
+	d.selected = idx
+	d.viewport.SetYOffset(d.fileOffsets[idx])
+	return d
+}
+
+func (d diffView) update(msg tea.KeyMsg) (diffView, bool) {
+	if d.searching {
+		switch msg.String() {
+		case "enter":
+			d.searching = false
+			d.searchInput.Blur()
+			d.query = d.searchInput.Value()
+			d = d.applySearch()
+			d = d.jumpToMatch(d.firstMatchFromOffset())
+		case "esc":
+			d.searching = false
+			d.searchInput.Blur()
+		default:
+			d.searchInput, _ = d.searchInput.Update(msg)
+		}
+		return d, true
+	}
+
+	switch msg.String() {
+	case "/":
+		d.searching = true
+		d.searchInput.SetValue(d.query)
+		d.searchInput.CursorEnd()
+		d.searchInput.Focus()
+	case "n":
+		if len(d.matches) > 0 {
+			d = d.jumpToMatch((d.matchIdx + 1) % len(d.matches))
+		}
+	case "N":
+		if len(d.matches) > 0 {
+			d = d.jumpToMatch((d.matchIdx - 1 + len(d.matches)) % len(d.matches))
+		}
+	case "g", "home":
+		d.viewport.GotoTop()
+		d.selected = d.fileAtOffset()
+	case "G", "end":
+		d.viewport.GotoBottom()
+		d.selected = d.fileAtOffset()
+	case "up", "k":
+		d.viewport.LineUp(1)
+		d.selected = d.fileAtOffset()
+	case "down", "j":
+		d.viewport.LineDown(1)
+		d.selected = d.fileAtOffset()
+	case "pgup", "b":
+		d.viewport.ViewUp()
+		d.selected = d.fileAtOffset()
+	case "pgdown", "f":
+		d.viewport.ViewDown()
+		d.selected = d.fileAtOffset()
+	case "tab":
+		d = d.selectFile(d.selected + 1)
+	case "shift+tab":
+		d = d.selectFile(d.selected - 1)
+	case "]":
+		for _, offset := range d.hunkOffsets {
+			if offset > d.viewport.YOffset {
+				d.viewport.SetYOffset(offset)
+				d.selected = d.fileAtOffset()
+				break
+			}
+		}
+	case "[":
+		for i := len(d.hunkOffsets) - 1; i >= 0; i-- {
+			if d.hunkOffsets[i] < d.viewport.YOffset {
+				d.viewport.SetYOffset(d.hunkOffsets[i])
+				d.selected = d.fileAtOffset()
+				break
+			}
+		}
+	case "s":
+		d.split = !d.split
+		d = d.render()
+		d = d.selectFile(d.selected)
+	case " ":
+		if len(d.fileOffsets) == 0 {
+			return d, true
+		}
+		d.collapsed[d.selected] = !d.collapsed[d.selected]
+		d = d.render()
+		d = d.selectFile(d.selected)
+	default:
+		return d, false
+	}
+	return d, true
+}
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// applySearch finds every occurrence of the query (case-insensitively) and
+// sets the viewport content with the matches highlighted. Lines with a match
+// lose their other styling so the highlight stays readable.
+func (d diffView) applySearch() diffView {
+	d.matches = nil
+	if d.query == "" {
+		d.viewport.SetContent(strings.Join(d.lines, "\n"))
+		return d
+	}
+
+	for i, line := range d.lines {
+		plain := ansiPattern.ReplaceAllString(line, "")
+		query := d.query
+		// Offsets must stay valid for the original text, which lowercasing
+		// some runes would break
+		if lower := strings.ToLower(plain); len(lower) == len(plain) {
+			plain = lower
+			query = strings.ToLower(query)
+		}
+		for start := 0; ; {
+			idx := strings.Index(plain[start:], query)
+			if idx == -1 {
+				break
+			}
+			d.matches = append(d.matches, searchMatch{line: i, start: start + idx, end: start + idx + len(query)})
+			start += idx + len(query)
+		}
+	}
+	d.matchIdx = min(d.matchIdx, max(0, len(d.matches)-1))
+
+	return d.highlightMatches()
+}
+
+func (d diffView) highlightMatches() diffView {
+	matchStyle := lipgloss.NewStyle().Background(lipgloss.Color("226")).Foreground(lipgloss.Color("0"))
+	currentStyle := lipgloss.NewStyle().Background(lipgloss.Color("208")).Foreground(lipgloss.Color("0")).Bold(true)
+
+	lines := make([]string, len(d.lines))
+	copy(lines, d.lines)
+
+	for i := 0; i < len(d.matches); {
+		lineIdx := d.matches[i].line
+		plain := ansiPattern.ReplaceAllString(d.lines[lineIdx], "")
+
+		var b strings.Builder
+		pos := 0
+		for ; i < len(d.matches) && d.matches[i].line == lineIdx; i++ {
+			match := d.matches[i]
+			b.WriteString(plain[pos:match.start])
+			style := matchStyle
+			if i == d.matchIdx {
+				style = currentStyle
+			}
+			b.WriteString(style.Render(plain[match.start:match.end]))
+			pos = match.end
+		}
+		b.WriteString(plain[pos:])
+		lines[lineIdx] = b.String()
+	}
+
+	d.viewport.SetContent(strings.Join(lines, "\n"))
+	return d
+}
+
+// firstMatchFromOffset is the first match at or below the top of the viewport.
+func (d diffView) firstMatchFromOffset() int {
+	for i, match := range d.matches {
+		if match.line >= d.viewport.YOffset {
+			return i
+		}
+	}
+	return 0
+}
+
+func (d diffView) jumpToMatch(idx int) diffView {
+	if idx < 0 || idx >= len(d.matches) {
+		return d
+	}
+	d.matchIdx = idx
+	d = d.highlightMatches()
+
+	// Keep a little context above the match
+	line := d.matches[idx].line
+	if line < d.viewport.YOffset || line >= d.viewport.YOffset+d.viewport.Height-2 {
+		d.viewport.SetYOffset(max(0, line-3))
+	}
+	d.selected = d.fileAtOffset()
+	return d
+}
+
+// searchStatus is the search prompt while typing, or the match count of the
+// active query.
+func (d diffView) searchStatus() string {
+	if d.searching {
+		return d.searchInput.View()
+	}
+	if d.query == "" {
+		return ""
+	}
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("226"))
+	if len(d.matches) == 0 {
+		return style.Render(fmt.Sprintf("/%s: no matches", d.query))
+	}
+	return style.Render(fmt.Sprintf("/%s: match %d of %d", d.query, d.matchIdx+1, len(d.matches)))
+}
+
+// stat renders a `git diff --stat` style summary line.
+func (d diffView) stat() string {
+	additions, deletions := 0, 0
+	for _, file := range d.files {
+		additions += file.Additions
+		deletions += file.Deletions
+	}
+
+	files := "files"
+	if len(d.files) == 1 {
+		files = "file"
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(fmt.Sprintf("%d %s changed, ", len(d.files), files)) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(fmt.Sprintf("%d insertions(+)", additions)) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(", ") +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(fmt.Sprintf("%d deletions(-)", deletions))
+}
+
+func (d diffView) View() string {
+	paneWidth := d.filePaneWidth()
+	if paneWidth == 0 {
+		return d.viewport.View()
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, d.renderFilePane(paneWidth), d.viewport.View())
+}
+
+func (d diffView) renderFilePane(width int) string {
+	innerWidth := width - 2
+	innerHeight := max(1, d.height-2)
+	current := d.selected
+
+	// Keep the current file visible when the list is taller than the pane
+	start := 0
+	if current >= innerHeight {
+		start = current - innerHeight + 1
+	}
+	end := min(len(d.files), start+innerHeight)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		file := d.files[i]
+
+		marker := "▾"
+		if d.collapsed[i] {
+			marker = "▸"
+		}
+
+		var statusColor lipgloss.Color
+		switch file.Status {
+		case "A":
+			statusColor = "46"
+		case "D":
+			statusColor = "196"
+		case "R", "C":
+			statusColor = "33"
+		default:
+			statusColor = "208"
+		}
+
+		counts := "bin"
+		if !file.Binary {
+			counts = fmt.Sprintf("+%d -%d", file.Additions, file.Deletions)
+		}
+
+		pathWidth := innerWidth - len(counts) - 5
+		path := truncateLeft(file.Path, pathWidth)
+		padding := strings.Repeat(" ", max(0, pathWidth-len([]rune(path))))
+
+		nameStyle := lipgloss.NewStyle()
+		if i == current {
+			nameStyle = nameStyle.Foreground(lipgloss.Color("170")).Bold(true)
+		}
+
+		lines = append(lines, marker+" "+
+			lipgloss.NewStyle().Foreground(statusColor).Render(file.Status)+" "+
+			nameStyle.Render(path)+padding+" "+
+			lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(counts))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("238")).
+		Width(innerWidth).
+		Height(innerHeight).
+		Render(strings.Join(lines, "\n"))
+}
+
+// truncateLeft keeps the end of a path, which is usually the interesting part.
+func truncateLeft(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 {
+		return ""
+	}
+	if len(runes) <= width {
+		return s
+	}
+	return "…" + string(runes[len(runes)-width+1:])
+}
+
+func (d diffView) helpText() string {
+	return "↑↓/j k: scroll • pgup/pgdown: page • tab/shift+tab: file • ]/[: hunk • space: fold • s: split • /: search • n/N: next/prev match • g/G: top/bottom"
+}
+
+func colorDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	var coloredLines []string
+
+	for _, line := range lines {
+		if len(line) == 0 {
+			coloredLines = append(coloredLines, line)
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			// Green background for added lines
+			if line == "+++" || strings.HasPrefix(line, "+++ ") {
+				// File header - use normal green text, no background
+				style := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+				coloredLines = append(coloredLines, style.Render(line))
+			} else {
+				// Added line - subtle green background
+				coloredLines = append(coloredLines, diffLineStyle('+', false).Render(line))
+			}
+		case '-':
+			// Red background for deleted lines
+			if line == "---" || strings.HasPrefix(line, "--- ") {
+				// File header - use normal red text, no background
+				style := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+				coloredLines = append(coloredLines, style.Render(line))
+			} else {
+				// Deleted line - subtle red background
+				coloredLines = append(coloredLines, diffLineStyle('-', false).Render(line))
+			}
+		case '@':
+			// Hunk headers (@@) - blue
+			if strings.HasPrefix(line, "@@") {
+				style := lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true)
+				coloredLines = append(coloredLines, style.Render(line))
+			} else {
+				coloredLines = append(coloredLines, line)
+			}
+		default:
+			// Context lines and other content - no special styling
+			coloredLines = append(coloredLines, line)
+		}
+	}
+
+	return strings.Join(coloredLines, "\n")
+}
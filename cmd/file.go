@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var fileCmd = &cobra.Command{
+	Use:   "f <path>",
+	Short: "Browse the history and blame of a file",
+	Long: `Lists the commits that touched a file, following it through renames, and
+annotates each line with the commit that last changed it. Enter opens a commit's
+diff; in the blame view p blames the line's previous version to dig further back.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The path is relative to where smak was started, before checkGitRepo
+		// moves to the top of the worktree
+		prefix, _ := internal.RepositoryPrefix()
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		file := path.Clean(path.Join(prefix, filepath.ToSlash(args[0])))
+
+		history, err := internal.GetFileHistory(file)
+		if err != nil {
+			fmt.Printf("Error getting history of %s: %v\n", file, err)
+			return
+		}
+		if len(history) == 0 {
+			fmt.Printf("No commits touch %s\n", file)
+			return
+		}
+
+		model := newFileModel(file, history, splitDiffDefault(cmd))
+		p := tea.NewProgram(model, tea.WithAltScreen())
+
+		if _, err := p.Run(); err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+	},
+}
+
+// blameState is a blamed version of the file, kept on a stack so that
+// re-blaming at a parent commit can be stepped back out of.
+type blameState struct {
+	rev    string
+	path   string
+	lines  []internal.BlameLine
+	cursor int
+	offset int
+}
+
+type fileModel struct {
+	path    string
+	history []internal.FileCommit
+	list    list.Model
+	width   int
+	height  int
+
+	blaming bool
+	blame   blameState
+	stack   []blameState
+
+	// detail shows a commit's diff using the commit browser's detail view
+	detail commitModel
+
+	message string
+}
+
+func newFileModel(file string, history []internal.FileCommit, splitDiff bool) fileModel {
+	commits := make([]internal.Commit, len(history))
+	for i, entry := range history {
+		commits[i] = entry.Commit
+	}
+	graph := internal.BuildGraph(commits)
+
+	items := make([]list.Item, len(history))
+	for i, entry := range history {
+		items[i] = commitItem{commit: entry.Commit, graph: graph[i]}
+	}
+
+	l := list.New(items, commitDelegate{DefaultDelegate: list.NewDefaultDelegate()}, 0, 0)
+	l.Title = "History of " + file
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	return fileModel{
+		path:    file,
+		history: history,
+		list:    l,
+		detail:  newCommitModel(nil, false, splitDiff),
+	}
+}
+
+func (m fileModel) Init() tea.Cmd {
+	return nil
+}
+
+// startBlame blames the file at rev ("" for the working tree), keeping the
+// cursor on the same line number where possible.
+func (m fileModel) startBlame(rev, file string, line int) fileModel {
+	lines, err := internal.Blame(rev, file)
+	if err != nil {
+		m.message = fmt.Sprintf("Error blaming %s: %v", file, err)
+		return m
+	}
+
+	m.blaming = true
+	m.blame = blameState{rev: rev, path: file, lines: lines}
+	m.blame.cursor = min(max(0, line), max(0, len(lines)-1))
+	m.blame.offset = max(0, m.blame.cursor-m.blameHeight()/2)
+	return m
+}
+
+// blameHeight is what is left for lines after the title, status and help.
+func (m fileModel) blameHeight() int {
+	if m.height == 0 {
+		return 30
+	}
+	return max(3, m.height-5)
+}
+
+func (m fileModel) openCommit(hash string) fileModel {
+	m.detail = m.detail.openCommit(hash)
+	return m
+}
+
+func (m fileModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.detail.showDiff {
+		updated, cmd := m.detail.Update(msg)
+		m.detail = updated.(commitModel)
+		return m, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		helpHeight := 4
+		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		updated, _ := m.detail.Update(msg)
+		m.detail = updated.(commitModel)
+		return m, nil
+
+	case tea.KeyMsg:
+		m.message = ""
+		if m.blaming {
+			return m.updateBlame(msg)
+		}
+
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "enter":
+			if len(m.history) > 0 {
+				return m.openCommit(m.history[m.list.Index()].Hash), nil
+			}
+			return m, nil
+		case "b":
+			if len(m.history) > 0 {
+				entry := m.history[m.list.Index()]
+				m.stack = nil
+				return m.startBlame(entry.Hash, entry.Path, 0), nil
+			}
+			return m, nil
+		case "tab":
+			m.stack = nil
+			return m.startBlame("", m.path, 0), nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m fileModel) updateBlame(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := &m.blame
+	page := m.blameHeight()
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		m.blaming = false
+		return m, nil
+	case "esc", "backspace":
+		if len(m.stack) == 0 {
+			m.blaming = false
+			return m, nil
+		}
+		m.blame = m.stack[len(m.stack)-1]
+		m.stack = m.stack[:len(m.stack)-1]
+		return m, nil
+	case "up", "k":
+		b.cursor--
+	case "down", "j":
+		b.cursor++
+	case "pgup":
+		b.cursor -= page
+	case "pgdown", " ":
+		b.cursor += page
+	case "g", "home":
+		b.cursor = 0
+	case "G", "end":
+		b.cursor = len(b.lines) - 1
+	case "enter":
+		if line, ok := m.currentLine(); ok {
+			if line.Uncommitted() {
+				m.message = "This line is not committed yet"
+				return m, nil
+			}
+			return m.openCommit(line.Hash), nil
+		}
+		return m, nil
+	case "p":
+		line, ok := m.currentLine()
+		switch {
+		case !ok:
+			return m, nil
+		case line.Uncommitted():
+			m.message = "This line is not committed yet; blame the last commit with b in the history instead"
+			return m, nil
+		case line.Previous == "":
+			m.message = fmt.Sprintf("%s added this line and has no earlier version of the file", shortHash(line.Hash))
+			return m, nil
+		}
+		m.stack = append(m.stack, m.blame)
+		return m.startBlame(line.Previous, line.PreviousPath, b.cursor), nil
+	}
+
+	b.cursor = min(max(0, b.cursor), max(0, len(b.lines)-1))
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+page {
+		b.offset = b.cursor - page + 1
+	}
+	return m, nil
+}
+
+func (m fileModel) currentLine() (internal.BlameLine, bool) {
+	if len(m.blame.lines) == 0 {
+		return internal.BlameLine{}, false
+	}
+	return m.blame.lines[m.blame.cursor], true
+}
+
+func (m fileModel) View() string {
+	if m.detail.showDiff {
+		return m.detail.View()
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	var status string
+	if m.message != "" {
+		status = "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render(m.message)
+	}
+
+	if !m.blaming {
+		help := "↑↓: navigate • enter: diff • b: blame at this commit • tab: blame working tree • q: quit"
+		return m.list.View() + status + "\n\n" + helpStyle.Render(help)
+	}
+
+	help := "↑↓/j k: move • pgup/pgdown: page • enter: commit diff • p: blame before this change • tab: history • q: quit"
+	if len(m.stack) > 0 {
+		help = strings.Replace(help, "tab: history", "esc: back", 1)
+	}
+	return m.renderBlame() + status + "\n\n" + helpStyle.Width(m.width).Render(help)
+}
+
+func (m fileModel) renderBlame() string {
+	b := m.blame
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+
+	version := "working tree"
+	if b.rev != "" {
+		version = shortHash(b.rev)
+		for _, entry := range m.history {
+			if entry.Hash == b.rev {
+				version += " " + entry.Message
+				break
+			}
+		}
+	}
+	title := titleStyle.Render("Blame: "+b.path) + dimStyle.Render(" @ "+version)
+	if len(m.stack) > 0 {
+		title += dimStyle.Render(fmt.Sprintf("  (%d back)", len(m.stack)))
+	}
+
+	width := m.width
+	if width == 0 {
+		width = 100
+	}
+	const gutterWidth = 40
+	lang := internal.LanguageForPath(b.path)
+	numberWidth := len(fmt.Sprint(len(b.lines)))
+
+	rows := []string{title, ""}
+	end := min(len(b.lines), b.offset+m.blameHeight())
+	for i := b.offset; i < end; i++ {
+		line := b.lines[i]
+
+		// Only the first line of a run from the same commit is annotated
+		var gutter string
+		if i == b.offset || b.lines[i-1].Hash != line.Hash {
+			if line.Uncommitted() {
+				gutter = "Not committed yet"
+			} else {
+				gutter = fmt.Sprintf("%s %s %s", shortHash(line.Hash), truncate(line.Author, 12), relativeTime(line.Date))
+			}
+		}
+		gutterStyle := dimStyle
+		marker := "  "
+		if i == b.cursor {
+			gutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+			marker = "▶ "
+			if gutter == "" {
+				gutter = fmt.Sprintf("%s %s", shortHash(line.Hash), truncate(line.Author, 12))
+			}
+		}
+
+		number := dimStyle.Render(fmt.Sprintf("%*d │ ", numberWidth, i+1))
+		text := truncate(strings.ReplaceAll(line.Content, "\t", "    "), max(10, width-gutterWidth-numberWidth-6))
+		rows = append(rows, marker+gutterStyle.Width(gutterWidth).MaxWidth(gutterWidth).Render(truncate(gutter, gutterWidth-1))+number+renderCode(text, lang, ' ', nil))
+	}
+	if len(b.lines) == 0 {
+		rows = append(rows, dimStyle.Render("The file is empty."))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func init() {
+	fileCmd.Flags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
+	rootCmd.AddCommand(fileCmd)
+}
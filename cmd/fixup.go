@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var fixupCmd = &cobra.Command{
+	Use:   "fixup <rev>",
+	Short: "Commit staged changes as a fixup for an older commit",
+	Long: `Commit the staged changes as a "fixup!" commit for rev, optionally folding it
+into rev right away with a non-interactive rebase --autosquash.
+
+Use -a to stage all changes first, or -i to choose files and hunks.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		autosquash, _ := cmd.Flags().GetBool("autosquash")
+
+		hash, err := internal.ResolveCommit(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		switch {
+		case all && interactive:
+			fmt.Println("Error: --all and --interactive cannot be used together")
+			return
+		case all:
+			if err := internal.StageFile(":/"); err != nil {
+				fmt.Printf("Error staging changes: %v\n", err)
+				return
+			}
+		case interactive:
+			confirmed, err := chooseAndStage("Choose changes to fix up "+shortHash(hash)+" with", "commit fixup")
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if !confirmed {
+				fmt.Println("Fixup cancelled")
+				return
+			}
+		}
+
+		if err := internal.CreateFixup(hash); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("Created fixup commit for %s\n", shortHash(hash))
+
+		if autosquash {
+			if err := internal.Autosquash(hash); err != nil {
+				fmt.Printf("Error squashing fixup (rebase aborted, fixup commit kept): %v\n", err)
+				return
+			}
+			fmt.Printf("Squashed fixup into %s\n", shortHash(hash))
+		}
+	},
+}
+
+// startFixup opens the fixup panel for the highlighted commit.
+func (m commitModel) startFixup() (tea.Model, tea.Cmd) {
+	if len(m.list.Items()) == 0 {
+		return m, nil
+	}
+
+	hash := m.commits[m.list.Index()].Hash
+	m.fixingUp = true
+	m.fixupHash = hash
+	m.fixupStaged = nil
+	m.fixupWarning = ""
+	m.fixupError = ""
+
+	entries, err := internal.GetStatus()
+	if err != nil {
+		m.fixupError = err.Error()
+		return m, nil
+	}
+	for _, entry := range entries {
+		if entry.Staged() {
+			m.fixupStaged = append(m.fixupStaged, entry.Path)
+		}
+	}
+
+	refs, err := internal.ProtectedRefsContaining(hash)
+	if err != nil {
+		log.Printf("Error checking remote branches: %v", err)
+	}
+	if len(refs) > 0 {
+		m.fixupWarning = fmt.Sprintf("This commit is already on protected %s; autosquashing rewrites shared history.", strings.Join(refs, ", "))
+	}
+
+	return m, nil
+}
+
+func (m commitModel) updateFixup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.fixingUp = false
+		return m, nil
+	case "enter", "a":
+		if len(m.fixupStaged) == 0 {
+			return m, nil
+		}
+		if err := internal.CreateFixup(m.fixupHash); err != nil {
+			m.fixupError = err.Error()
+			return m, nil
+		}
+		if msg.String() == "a" {
+			if err := internal.Autosquash(m.fixupHash); err != nil {
+				// The fixup commit exists, so reload history before reporting
+				model, cmd := m.refreshCommits()
+				refreshed := model.(commitModel)
+				refreshed.fixingUp = true
+				refreshed.fixupHash = m.fixupHash
+				refreshed.fixupError = "Fixup committed, but the autosquash rebase failed and was aborted: " + err.Error()
+				return refreshed, cmd
+			}
+		}
+		return m.refreshCommits()
+	}
+	return m, nil
+}
+
+func (m commitModel) renderFixup() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	fileStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	subject := ""
+	for _, commit := range m.commits {
+		if commit.Hash == m.fixupHash {
+			subject = commit.Message
+		}
+	}
+
+	lines := []string{
+		titleStyle.Render("Fix up " + shortHash(m.fixupHash) + " " + subject),
+		"",
+	}
+
+	help := "esc: cancel"
+	if m.fixupError != "" && len(m.fixupStaged) == 0 {
+		// Shown after a failed autosquash, when there is nothing left to commit
+		help = "esc: close"
+	} else if len(m.fixupStaged) == 0 {
+		lines = append(lines,
+			dimStyle.Render("Nothing is staged. Stage the changes to fold into this commit first,"),
+			dimStyle.Render("or run `smak c fixup -i "+shortHash(m.fixupHash)+"` to pick files and hunks."),
+		)
+	} else {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("%d staged file(s) will be committed as fixup! %s:", len(m.fixupStaged), subject)))
+		for _, path := range m.fixupStaged {
+			lines = append(lines, "  "+fileStyle.Render(path))
+		}
+		help = "enter: commit fixup • a: commit fixup and autosquash • esc: cancel"
+	}
+
+	lines = append(lines, "")
+	if m.fixupWarning != "" {
+		lines = append(lines, warningStyle.Render(m.fixupWarning))
+	}
+	if m.fixupError != "" {
+		lines = append(lines, errorStyle.Render(m.fixupError))
+	}
+	lines = append(lines, helpStyle.Render(help))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
@@ -15,13 +15,38 @@ var helpCmd = &cobra.Command{
 		fmt.Println()
 		fmt.Println("Available commands:")
 		fmt.Println("  smak b      Browse and manage branches interactively")
+		fmt.Println("  smak s      Stage, unstage, discard and commit changes")
+		fmt.Println("  smak commit Compose a Conventional Commits message and commit")
+		fmt.Println("  smak st     Browse, apply, pop, drop and create stashes")
+		fmt.Println("  smak t      Browse, create, push and delete tags")
+		fmt.Println("  smak w      Add, remove, lock and pick worktrees (cd \"$(smak w --pick)\")")
+		fmt.Println("  smak r      Add, remove, rename and fetch remotes, set the default remote")
+		fmt.Println("  smak sync   Fetch all remotes and fast-forward local branches")
+		fmt.Println("  smak bisect Find the commit that introduced a bug (smak bisect run <script> to automate)")
+		fmt.Println("  smak undo   Browse the reflog and reset or branch back to an earlier state")
+		fmt.Println("  smak f <path>  Browse a file's history and blame")
 		fmt.Println("  smak c      Browse commits in current branch")
+		fmt.Println("  smak c am   Stage all changes and amend to latest commit")
+		fmt.Println("  smak c am -i  Choose files and hunks to amend")
+		fmt.Println("  smak c rebase <base>  Interactively rebase onto base")
+		fmt.Println("  smak c fixup <rev>    Commit staged changes as a fixup for rev")
 		fmt.Println("  smak help   Show this help information")
 		fmt.Println()
+		fmt.Println("Global flags:")
+		fmt.Println("  --dry-run   Show git commands that would change the repository instead of running them")
+		fmt.Println()
 		fmt.Println("Interactive controls:")
 		fmt.Println("  ↑↓          Navigate through items")
 		fmt.Println("  Enter       Select item")
 		fmt.Println("  d           Toggle selection for deletion (in branch view)")
+		fmt.Println("  p / u / f   Push / pull / fetch (in branch view)")
+		fmt.Println("  Space       Select commits (in commit view)")
+		fmt.Println("  p / P       Cherry-pick onto current / chosen branch (in commit view)")
+		fmt.Println("  v           Revert commit (in commit view)")
+		fmt.Println("  r           Reword commit message (in commit view)")
+		fmt.Println("  f           Commit staged changes as a fixup (in commit view)")
+		fmt.Println("  c           Compare two branches / commits (in branch and commit view)")
+		fmt.Println("  t           Toggle commit graph (in commit view)")
 		fmt.Println("  Escape      Return to previous screen")
 		fmt.Println("  q           Quit")
 	},
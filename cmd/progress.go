@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+// gitProgressMsg is a line of output from a git command running in the
+// background, such as a push, pull or fetch.
+type gitProgressMsg string
+
+// gitDoneMsg reports that the background git command ended.
+type gitDoneMsg struct {
+	err error
+}
+
+// runGitAsync starts cmd and lets it run in the background. Its output
+// arrives as gitProgressMsg and its end as gitDoneMsg on the returned
+// channel; the model waits for each one in turn with waitForGit. cmd has
+// been started by the time runGitAsync returns, so the model may stop it.
+func runGitAsync(cmd *exec.Cmd) (chan tea.Msg, tea.Cmd) {
+	events := make(chan tea.Msg)
+	finish, err := internal.StartWithProgress(cmd)
+	go func() {
+		if err == nil {
+			err = finish(func(line string) {
+				events <- gitProgressMsg(line)
+			})
+		}
+		events <- gitDoneMsg{err: err}
+	}()
+	return events, waitForGit(events)
+}
+
+func waitForGit(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
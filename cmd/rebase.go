@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase <base>",
+	Short: "Interactively rebase the current branch onto base",
+	Long:  `Reorder, reword, edit, squash, fixup or drop the commits since base in an interactive editor, then run the rebase.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		commits, err := internal.GetCommitRange(args[0])
+		if err != nil {
+			fmt.Printf("Error getting commits: %v\n", err)
+			return
+		}
+		if len(commits) == 0 {
+			fmt.Printf("No commits between %s and HEAD\n", args[0])
+			return
+		}
+
+		model := newRebaseModel(args[0], commits)
+		p := tea.NewProgram(model, tea.WithAltScreen())
+
+		if _, err := p.Run(); err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+	},
+}
+
+var rebaseActionColors = map[string]lipgloss.Color{
+	"pick":   "46",
+	"reword": "33",
+	"edit":   "226",
+	"squash": "170",
+	"fixup":  "208",
+	"drop":   "196",
+}
+
+type rebaseStepItem struct {
+	step internal.RebaseStep
+}
+
+func (i rebaseStepItem) Title() string {
+	return fmt.Sprintf("%-6s %s - %s", i.step.Action, shortHash(i.step.Commit.Hash), i.step.Commit.Message)
+}
+
+func (i rebaseStepItem) Description() string {
+	return fmt.Sprintf("%s by %s", i.step.Commit.Date.Format("2006-01-02 15:04:05"), i.step.Commit.Author)
+}
+
+func (i rebaseStepItem) FilterValue() string {
+	return i.step.Commit.Message
+}
+
+type rebaseDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d rebaseDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item, ok := listItem.(rebaseStepItem)
+	if !ok {
+		d.DefaultDelegate.Render(w, m, index, listItem)
+		return
+	}
+
+	actionStyle := lipgloss.NewStyle().Foreground(rebaseActionColors[item.step.Action]).Bold(true)
+	titleStyle := lipgloss.NewStyle()
+	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	cursor := "  "
+	if index == m.Index() {
+		titleStyle = titleStyle.Foreground(lipgloss.Color("170"))
+		descStyle = descStyle.Foreground(lipgloss.Color("243"))
+		cursor = "> "
+	}
+	if item.step.Action == "drop" {
+		titleStyle = titleStyle.Strikethrough(true)
+	}
+
+	title := fmt.Sprintf("%s - %s", shortHash(item.step.Commit.Hash), item.step.Commit.Message)
+	fmt.Fprint(w, cursor+actionStyle.Render(fmt.Sprintf("%-6s", item.step.Action))+" "+titleStyle.Render(truncate(title, m.Width()-10)))
+	fmt.Fprint(w, "\n")
+	fmt.Fprint(w, "         "+descStyle.Render(item.Description()))
+}
+
+type rebaseExecMsg struct {
+	err    error
+	output string
+}
+
+type rebaseModel struct {
+	list         list.Model
+	base         string
+	steps        []internal.RebaseStep
+	todoPath     string
+	helpVisible  bool
+	running      bool
+	stopped      bool
+	done         bool
+	state        *internal.RebaseState
+	errorMessage string
+}
+
+func newRebaseModel(base string, commits []internal.Commit) rebaseModel {
+	// The todo list is applied oldest first
+	steps := make([]internal.RebaseStep, len(commits))
+	for i, commit := range commits {
+		steps[len(commits)-1-i] = internal.RebaseStep{Action: "pick", Commit: commit}
+	}
+
+	l := list.New(nil, rebaseDelegate{DefaultDelegate: list.NewDefaultDelegate()}, 0, 0)
+	l.Title = fmt.Sprintf("Rebase onto %s (oldest first)", base)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	m := rebaseModel{
+		list:        l,
+		base:        base,
+		steps:       steps,
+		helpVisible: true,
+	}
+	return m.updateListItems()
+}
+
+func (m rebaseModel) updateListItems() rebaseModel {
+	items := make([]list.Item, len(m.steps))
+	for i, step := range m.steps {
+		items[i] = rebaseStepItem{step: step}
+	}
+	m.list.SetItems(items)
+	return m
+}
+
+func (m rebaseModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m rebaseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		helpHeight := 4
+		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		return m, nil
+
+	case rebaseExecMsg:
+		return m.afterRebaseCommand(msg)
+
+	case tea.KeyMsg:
+		if m.running {
+			return m, nil
+		}
+		if m.done {
+			switch msg.String() {
+			case "enter", "q", "esc", "ctrl+c":
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.stopped {
+			return m.updateStopped(msg)
+		}
+
+		idx := m.list.Index()
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "p", "r", "e", "s", "f", "d":
+			for _, action := range internal.RebaseActions {
+				if strings.HasPrefix(action, msg.String()) && len(m.steps) > 0 {
+					m.steps[idx].Action = action
+				}
+			}
+			m.errorMessage = ""
+			return m.updateListItems(), nil
+		case "shift+up", "K":
+			if idx > 0 {
+				m.steps[idx], m.steps[idx-1] = m.steps[idx-1], m.steps[idx]
+				m = m.updateListItems()
+				m.list.Select(idx - 1)
+			}
+			return m, nil
+		case "shift+down", "J":
+			if idx < len(m.steps)-1 {
+				m.steps[idx], m.steps[idx+1] = m.steps[idx+1], m.steps[idx]
+				m = m.updateListItems()
+				m.list.Select(idx + 1)
+			}
+			return m, nil
+		case "enter":
+			return m.startRebase()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m rebaseModel) startRebase() (tea.Model, tea.Cmd) {
+	// squash and fixup need an earlier commit to fold into
+	for _, step := range m.steps {
+		if step.Action == "drop" {
+			continue
+		}
+		if step.Action == "squash" || step.Action == "fixup" {
+			m.errorMessage = "The first commit cannot be squashed or fixed up"
+			return m, nil
+		}
+		break
+	}
+
+	todoPath, err := internal.WriteRebaseTodo(m.steps)
+	if err != nil {
+		m.errorMessage = err.Error()
+		return m, nil
+	}
+	m.todoPath = todoPath
+	m.running = true
+
+	return m, runGitAttached(internal.RebaseCommand(m.base, todoPath))
+}
+
+// runGitAttached hands the terminal to git (editors may open) and captures
+// its error output so it can be shown once smak takes over again.
+func runGitAttached(cmd *exec.Cmd) tea.Cmd {
+	var stderr bytes.Buffer
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return rebaseExecMsg{err: err, output: stderr.String()}
+	})
+}
+
+func (m rebaseModel) updateStopped(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		cmd, result, err := internal.ContinueRebaseCommand()
+		if err != nil {
+			m.errorMessage = err.Error()
+			return m, nil
+		}
+		if result != nil {
+			m.state.ConflictFiles = result.ConflictFiles
+			m.errorMessage = "Some files still contain conflict markers"
+			return m, nil
+		}
+		m.running = true
+		return m, runGitAttached(cmd)
+	case "s":
+		m.running = true
+		return m, runGitAttached(internal.SkipRebaseCommand())
+	case "esc":
+		if err := internal.AbortRebase(); err != nil {
+			m.errorMessage = err.Error()
+			return m, nil
+		}
+		m.stopped = false
+		m.done = true
+		m.errorMessage = "Rebase aborted, branch restored"
+		return m, nil
+	case "q", "ctrl+c":
+		// Leave the rebase in progress for the user to finish by hand
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m rebaseModel) afterRebaseCommand(msg rebaseExecMsg) (tea.Model, tea.Cmd) {
+	m.running = false
+	m.errorMessage = ""
+
+	// git copied the todo when the rebase started, so it is not needed for
+	// continuing, aborting or quitting either
+	if m.todoPath != "" {
+		os.Remove(m.todoPath)
+		m.todoPath = ""
+	}
+
+	state, err := internal.GetRebaseState()
+	if err != nil {
+		m.done = true
+		m.errorMessage = err.Error()
+		return m, nil
+	}
+
+	if state.InProgress {
+		m.stopped = true
+		m.state = state
+		return m, nil
+	}
+
+	m.stopped = false
+	m.done = true
+	if msg.err != nil {
+		m.errorMessage = strings.TrimSpace(msg.output)
+		if m.errorMessage == "" {
+			m.errorMessage = msg.err.Error()
+		}
+	}
+	return m, nil
+}
+
+func (m rebaseModel) View() string {
+	if m.running {
+		return "\n  Running git rebase…\n"
+	}
+	if m.done || m.stopped {
+		return m.renderStatus()
+	}
+
+	view := m.list.View()
+
+	if m.helpVisible {
+		helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+		help := helpStyle.Render("p: pick • r: reword • e: edit • s: squash • f: fixup • d: drop • shift+↑↓/K J: move • enter: start rebase • esc/q: quit")
+		if m.errorMessage != "" {
+			help = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n" + help
+		}
+		view += "\n\n" + help
+	}
+
+	return view
+}
+
+func (m rebaseModel) renderStatus() string {
+	var content []string
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("170")).
+		Bold(true).
+		Padding(1, 2)
+	content = append(content, titleStyle.Render(fmt.Sprintf("Rebase onto %s", m.base)))
+
+	statusStyle := lipgloss.NewStyle().
+		Padding(0, 2).
+		Margin(1, 0)
+	filesStyle := lipgloss.NewStyle().
+		Padding(0, 4).
+		Foreground(lipgloss.Color("243"))
+
+	var helpText string
+	switch {
+	case m.done && m.errorMessage == "":
+		content = append(content, statusStyle.Copy().Foreground(lipgloss.Color("46")).Render("✓ Rebase completed successfully"))
+		helpText = "enter: quit"
+	case m.done:
+		content = append(content, statusStyle.Copy().Foreground(lipgloss.Color("196")).Render("✗ "+m.errorMessage))
+		helpText = "enter: quit"
+	default:
+		progress := ""
+		if m.state.Total > 0 {
+			progress = fmt.Sprintf(" (step %d of %d)", m.state.Step, m.state.Total)
+		}
+
+		if len(m.state.ConflictFiles) > 0 {
+			content = append(content, statusStyle.Copy().Foreground(lipgloss.Color("196")).
+				Render(fmt.Sprintf("✗ Conflicts while applying %s%s", shortHash(m.state.StoppedAt), progress)))
+			content = append(content, filesStyle.Render("Conflicted files:"))
+			for _, file := range m.state.ConflictFiles {
+				content = append(content, filesStyle.Render("• "+file))
+			}
+			content = append(content, filesStyle.Render("Resolve the conflicts in your editor, then continue."))
+		} else {
+			content = append(content, statusStyle.Copy().Foreground(lipgloss.Color("226")).
+				Render(fmt.Sprintf("■ Stopped at %s%s", shortHash(m.state.StoppedAt), progress)))
+			content = append(content, filesStyle.Render("Make your changes and amend them (e.g. smak c am), then continue."))
+		}
+		if m.errorMessage != "" {
+			content = append(content, statusStyle.Copy().Foreground(lipgloss.Color("196")).Render(m.errorMessage))
+		}
+		helpText = "enter: continue • s: skip commit • esc: abort rebase • q: quit and finish by hand"
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Padding(1, 2).
+		Margin(1, 0)
+	content = append(content, helpStyle.Render(helpText))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("238")).
+		Padding(1).
+		Margin(2)
+
+	return containerStyle.Render(strings.Join(content, "\n"))
+}
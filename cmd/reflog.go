@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var reflogCmd = &cobra.Command{
+	Use:     "reflog [branch]",
+	Aliases: []string{"undo"},
+	Short:   "Browse the reflog and undo history changes",
+	Long: `Lists where HEAD (or a branch) pointed before each commit, checkout, reset,
+rebase or merge, with a plain description of every step and a preview of what
+changed since. Reset the current branch to an entry or create a branch there
+to get back work lost to a botched rebase or reset.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Without a HEAD branch (detached) only the HEAD reflog is available
+		branch, _ := internal.CurrentBranch()
+		ref := "HEAD"
+		if len(args) > 0 {
+			ref = args[0]
+		}
+
+		model, err := newReflogModel(ref, branch, splitDiffDefault(cmd))
+		if err != nil {
+			fmt.Printf("Error reading the reflog of %s: %v\n", ref, err)
+			return
+		}
+		p := tea.NewProgram(model, tea.WithAltScreen())
+
+		if _, err := p.Run(); err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+	},
+}
+
+type reflogItem struct {
+	entry internal.ReflogEntry
+}
+
+func (i reflogItem) Title() string {
+	return i.entry.Selector + "  " + i.entry.Description()
+}
+
+func (i reflogItem) Description() string {
+	return fmt.Sprintf("%s | %s | %s", shortHash(i.entry.Hash), relativeTime(i.entry.Date), i.entry.CommitSubject)
+}
+
+func (i reflogItem) FilterValue() string {
+	return i.entry.Subject
+}
+
+// resetModeLabels explain what each reset mode keeps.
+var resetModeLabels = map[string]string{
+	internal.ResetSoft:  "keep all changes staged",
+	internal.ResetMixed: "keep all changes, unstaged",
+	internal.ResetHard:  "discard uncommitted changes",
+}
+
+type reflogModel struct {
+	list    list.Model
+	entries []internal.ReflogEntry
+	ref     string
+	branch  string
+	width   int
+	height  int
+
+	preview diffView
+	// previewHash is the entry the preview was last loaded for
+	previewHash string
+	showDiff    bool
+
+	choosingReset bool
+	resetMode     string
+	naming        bool
+	nameInput     textinput.Model
+
+	message string
+	isError bool
+}
+
+func newReflogModel(ref, branch string, splitDiff bool) (reflogModel, error) {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	m := reflogModel{
+		list:    l,
+		ref:     ref,
+		branch:  branch,
+		preview: newDiffView(splitDiff),
+	}
+	return m.reload()
+}
+
+func (m reflogModel) reload() (reflogModel, error) {
+	entries, err := internal.GetReflog(m.ref)
+	if err != nil {
+		return m, err
+	}
+
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[i] = reflogItem{entry: entry}
+	}
+	m.entries = entries
+	m.list.SetItems(items)
+	m.list.Title = "Reflog of " + m.ref
+	m.list.Select(0)
+	m.previewHash = ""
+	return m.updatePreview(), nil
+}
+
+// canReset reports whether the shown reflog is one the current branch can be
+// reset along: HEAD's or the branch's own. Entries of another branch's reflog
+// can only be branched from.
+func (m reflogModel) canReset() bool {
+	return m.branch != "" && (m.ref == "HEAD" || m.ref == m.branch || m.ref == "refs/heads/"+m.branch)
+}
+
+func (m reflogModel) current() (internal.ReflogEntry, bool) {
+	if len(m.entries) == 0 {
+		return internal.ReflogEntry{}, false
+	}
+	return m.entries[m.list.Index()], true
+}
+
+// updatePreview shows what going back from the current HEAD to the entry
+// would change.
+func (m reflogModel) updatePreview() reflogModel {
+	entry, ok := m.current()
+	if !ok || entry.Hash == m.previewHash {
+		return m
+	}
+	raw, err := internal.GetDiffBetween("HEAD", entry.Hash)
+	if err != nil {
+		raw = ""
+	}
+	m.preview = m.preview.setDiff(raw, nil)
+	m.previewHash = entry.Hash
+	return m.layout()
+}
+
+func (m reflogModel) listWidth() int {
+	if m.width >= 100 {
+		return m.width * 2 / 5
+	}
+	return m.width
+}
+
+func (m reflogModel) layout() reflogModel {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = 100, 40
+	}
+
+	h, v := lipgloss.NewStyle().GetFrameSize()
+	helpHeight := 4
+	m.list.SetSize(m.listWidth()-h, height-v-helpHeight)
+	if m.showDiff {
+		m.preview = m.preview.setSize(width, height-4)
+	} else {
+		m.preview = m.preview.setSize(width-m.listWidth(), height-v-helpHeight)
+	}
+	return m
+}
+
+func (m reflogModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m reflogModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m.layout(), nil
+
+	case tea.KeyMsg:
+		if m.naming {
+			return m.updateName(msg)
+		}
+
+		if m.showDiff {
+			if !m.preview.searching {
+				switch msg.String() {
+				case "q", "esc":
+					m.showDiff = false
+					return m.layout(), nil
+				}
+			}
+			m.preview, _ = m.preview.update(msg)
+			return m, nil
+		}
+
+		if m.resetMode != "" {
+			mode := m.resetMode
+			m.resetMode = ""
+			if msg.String() == "y" {
+				return m.reset(mode), nil
+			}
+			return m, nil
+		}
+
+		if m.choosingReset {
+			m.choosingReset = false
+			switch msg.String() {
+			case "s":
+				m.resetMode = internal.ResetSoft
+			case "m":
+				m.resetMode = internal.ResetMixed
+			case "h":
+				m.resetMode = internal.ResetHard
+			}
+			return m, nil
+		}
+
+		m.message = ""
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "tab":
+			if m.branch == "" {
+				m.message, m.isError = "HEAD is detached, there is no branch reflog to show", true
+				return m, nil
+			}
+			previous := m.ref
+			m.ref = m.branch
+			if previous != "HEAD" {
+				m.ref = "HEAD"
+			}
+			reloaded, err := m.reload()
+			if err != nil {
+				m.ref = previous
+				m.message, m.isError = "Error reading the reflog of "+reloaded.ref+": "+err.Error(), true
+				return m, nil
+			}
+			return reloaded, nil
+		}
+
+		if _, ok := m.current(); !ok {
+			break
+		}
+		switch msg.String() {
+		case "enter":
+			m.showDiff = true
+			return m.layout(), nil
+		case "r":
+			if m.branch == "" {
+				m.message, m.isError = "HEAD is detached; create a branch at the entry with b instead", true
+				return m, nil
+			}
+			if !m.canReset() {
+				m.message, m.isError = fmt.Sprintf("This is the reflog of %s, not of %s; create a branch at the entry with b instead", m.ref, m.branch), true
+				return m, nil
+			}
+			m.choosingReset = true
+			return m, nil
+		case "b":
+			input := textinput.New()
+			input.Placeholder = "new branch name"
+			input.Width = max(20, m.width-10)
+			input.Focus()
+			m.nameInput = input
+			m.naming = true
+			return m, textinput.Blink
+		}
+	}
+
+	if m.naming {
+		var cmd tea.Cmd
+		m.nameInput, cmd = m.nameInput.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m.updatePreview(), cmd
+}
+
+func (m reflogModel) reset(mode string) reflogModel {
+	entry, _ := m.current()
+	if err := internal.ResetTo(entry.Hash, mode); err != nil {
+		m.message, m.isError = "Error resetting: "+err.Error(), true
+		return m
+	}
+
+	reloaded, err := m.reload()
+	if err != nil {
+		m.message, m.isError = "Error reloading the reflog: "+err.Error(), true
+		return m
+	}
+	m = reloaded
+	// The reset itself is now the newest entry, so the previous state is one step back
+	m.message, m.isError = fmt.Sprintf("Reset %s to %s (--%s). Changed your mind? Reset to %s@{1}", m.branch, shortHash(entry.Hash), mode, m.ref), false
+	return m
+}
+
+func (m reflogModel) updateName(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.naming = false
+		return m, nil
+	case "enter":
+		name := strings.TrimSpace(m.nameInput.Value())
+		if name == "" {
+			return m, nil
+		}
+		m.naming = false
+		entry, _ := m.current()
+		if err := internal.CreateBranchAt(name, entry.Hash); err != nil {
+			m.message, m.isError = "Error creating branch: "+err.Error(), true
+			return m, nil
+		}
+		m.message, m.isError = fmt.Sprintf("Created branch %s at %s", name, shortHash(entry.Hash)), false
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+func (m reflogModel) View() string {
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	entry, _ := m.current()
+
+	if m.showDiff {
+		title := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true).
+			Render("Changes from the current HEAD to " + entry.Selector)
+		return lipgloss.JoinVertical(lipgloss.Left,
+			title+"  "+m.preview.stat(),
+			m.preview.View(),
+			"",
+			helpStyle.Width(m.width).Render(m.preview.helpText()+" • esc: back"),
+		)
+	}
+
+	body := m.list.View()
+	if len(m.entries) == 0 {
+		body = lipgloss.NewStyle().Padding(1, 2).Render("The reflog of " + m.ref + " is empty.")
+	} else if m.width >= 100 {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(m.listWidth()).Render(body), m.preview.View())
+	}
+
+	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true)
+	var status string
+	switch {
+	case m.naming:
+		status = "Create a branch at " + entry.Selector + " (" + shortHash(entry.Hash) + "):\n" + m.nameInput.View()
+	case m.choosingReset:
+		status = warningStyle.Render(fmt.Sprintf("Reset %s to %s (%s): s: soft, %s • m: mixed, %s • h: hard, %s • any other key: cancel",
+			m.branch, entry.Selector, shortHash(entry.Hash),
+			resetModeLabels[internal.ResetSoft], resetModeLabels[internal.ResetMixed], resetModeLabels[internal.ResetHard]))
+	case m.resetMode != "":
+		text := fmt.Sprintf("Reset %s to %s with --%s (%s)? y: reset • any other key: cancel", m.branch, shortHash(entry.Hash), m.resetMode, resetModeLabels[m.resetMode])
+		if m.resetMode == internal.ResetHard {
+			text = fmt.Sprintf("Reset %s to %s with --hard? Uncommitted changes to tracked files are lost for good. y: reset • any other key: cancel", m.branch, shortHash(entry.Hash))
+		}
+		status = warningStyle.Render(text)
+	case m.message != "" && m.isError:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	case m.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	}
+	if status != "" {
+		body += "\n" + status
+	}
+
+	help := "↑↓: navigate • enter: diff • b: branch here • tab: HEAD/branch reflog • q: quit"
+	if m.canReset() {
+		help = "↑↓: navigate • enter: diff • r: reset branch here • b: branch here • tab: HEAD/branch reflog • q: quit"
+	}
+	if m.naming {
+		help = "enter: create • esc: cancel"
+	}
+	return body + "\n\n" + helpStyle.Render(help)
+}
+
+func init() {
+	reflogCmd.Flags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
+	rootCmd.AddCommand(reflogCmd)
+}
@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var remoteCmd = &cobra.Command{
+	Use:   "r",
+	Short: "Browse and manage remotes",
+	Long: `Interactive remote manager: list remotes with their fetch and push URLs and
+refspecs, add, remove and rename remotes, fetch them, and choose the default
+remote. The default remote (git config smak.defaultRemote, else origin) is where
+smak pushes branches without an upstream and tags, and what branches without an
+upstream are compared with.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		remotes, err := internal.GetRemotes()
+		if err != nil {
+			fmt.Printf("Error getting remotes: %v\n", err)
+			return
+		}
+
+		p := tea.NewProgram(newRemoteModel(remotes), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+	},
+}
+
+type remoteItem struct {
+	remote internal.Remote
+}
+
+func (i remoteItem) Title() string {
+	if i.remote.Default {
+		return i.remote.Name + " [default]"
+	}
+	return i.remote.Name
+}
+
+func (i remoteItem) Description() string {
+	if i.remote.PushURL != "" && i.remote.PushURL != i.remote.FetchURL {
+		return i.remote.FetchURL + " (push: " + i.remote.PushURL + ")"
+	}
+	return i.remote.FetchURL
+}
+
+func (i remoteItem) FilterValue() string {
+	return i.remote.Name
+}
+
+// remotePrompt is the input a text prompt in the remote view is collecting.
+type remotePrompt int
+
+const (
+	remotePromptNone remotePrompt = iota
+	remotePromptName
+	remotePromptURL
+	remotePromptRename
+)
+
+type remoteModel struct {
+	list    list.Model
+	remotes []internal.Remote
+	width   int
+	height  int
+
+	prompt  remotePrompt
+	input   textinput.Model
+	newName string
+
+	confirmRemove bool
+	message       string
+	isError       bool
+
+	// running describes the fetch in progress and progress holds the last
+	// line git printed for it
+	running  string
+	progress string
+	events   chan tea.Msg
+	success  string
+}
+
+func newRemoteModel(remotes []internal.Remote) remoteModel {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Remotes"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	m := remoteModel{list: l}
+	return m.setRemotes(remotes)
+}
+
+func (m remoteModel) setRemotes(remotes []internal.Remote) remoteModel {
+	items := make([]list.Item, len(remotes))
+	for i, remote := range remotes {
+		items[i] = remoteItem{remote: remote}
+	}
+	m.remotes = remotes
+	m.list.SetItems(items)
+	if m.list.Index() >= len(items) {
+		m.list.Select(max(0, len(items)-1))
+	}
+	return m
+}
+
+func (m remoteModel) current() (internal.Remote, bool) {
+	if len(m.remotes) == 0 {
+		return internal.Remote{}, false
+	}
+	return m.remotes[m.list.Index()], true
+}
+
+// selectRemote moves the highlight to the named remote.
+func (m remoteModel) selectRemote(name string) remoteModel {
+	for i, remote := range m.remotes {
+		if remote.Name == name {
+			m.list.Select(i)
+		}
+	}
+	return m
+}
+
+func (m remoteModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m remoteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case gitProgressMsg:
+		m.progress = string(msg)
+		return m, waitForGit(m.events)
+
+	case gitDoneMsg:
+		m.running, m.events, m.progress = "", nil, ""
+		return m.run(msg.err, m.success), nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		// Room for the details of the highlighted remote, status and help
+		m.list.SetSize(msg.Width-h, max(4, msg.Height-v-12))
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running != "" {
+			// Only navigation is allowed until the fetch finishes
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "up", "down", "k", "j":
+				var cmd tea.Cmd
+				m.list, cmd = m.list.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		if m.prompt != remotePromptNone {
+			return m.updatePrompt(msg)
+		}
+
+		if m.confirmRemove {
+			m.confirmRemove = false
+			if remote, ok := m.current(); ok && msg.String() == "y" {
+				return m.run(internal.RemoveRemote(remote.Name), "Removed "+remote.Name), nil
+			}
+			return m, nil
+		}
+
+		m.message = ""
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "a":
+			return m.startPrompt(remotePromptName, "", "name of the new remote")
+		case "F":
+			if len(m.remotes) == 0 {
+				break
+			}
+			return m.startFetch("Fetching all remotes", "Fetched all remotes", internal.FetchAllCommand())
+		}
+
+		remote, ok := m.current()
+		if !ok {
+			break
+		}
+		switch msg.String() {
+		case "d":
+			m.confirmRemove = true
+			return m, nil
+		case "r":
+			return m.startPrompt(remotePromptRename, remote.Name, "new name")
+		case "s":
+			if remote.Default {
+				m.message, m.isError = remote.Name+" is already the default remote", false
+				return m, nil
+			}
+			return m.run(internal.SetDefaultRemote(remote.Name), remote.Name+" is now the default remote"), nil
+		case "f":
+			return m.startFetch("Fetching "+remote.Name, "Fetched "+remote.Name, internal.FetchRemoteCommand(remote.Name))
+		}
+	}
+
+	if m.prompt != remotePromptNone {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m remoteModel) startFetch(label, success string, cmd *exec.Cmd) (tea.Model, tea.Cmd) {
+	events, wait := runGitAsync(cmd)
+	m.running, m.success, m.events = label, success, events
+	return m, wait
+}
+
+func (m remoteModel) startPrompt(prompt remotePrompt, value, placeholder string) (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.SetValue(value)
+	input.Width = max(20, m.width-10)
+	input.Focus()
+
+	m.prompt = prompt
+	m.input = input
+	return m, textinput.Blink
+}
+
+func (m remoteModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.prompt = remotePromptNone
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.input.Value())
+		if value == "" {
+			return m, nil
+		}
+		prompt := m.prompt
+		m.prompt = remotePromptNone
+
+		switch prompt {
+		case remotePromptName:
+			m.newName = value
+			return m.startPrompt(remotePromptURL, "", "URL or path of "+value)
+		case remotePromptURL:
+			m = m.run(internal.AddRemote(m.newName, value), "Added "+m.newName+" (f: fetch it)")
+			return m.selectRemote(m.newName), nil
+		case remotePromptRename:
+			remote, ok := m.current()
+			if !ok || value == remote.Name {
+				return m, nil
+			}
+			m = m.run(internal.RenameRemote(remote.Name, value), "Renamed "+remote.Name+" to "+value)
+			return m.selectRemote(value), nil
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// run reports the outcome of an action and reloads the remotes.
+func (m remoteModel) run(err error, success string) remoteModel {
+	remotes, listErr := internal.GetRemotes()
+	if listErr == nil {
+		m = m.setRemotes(remotes)
+	}
+
+	switch {
+	case err != nil:
+		m.message, m.isError = err.Error(), true
+	case listErr != nil:
+		m.message, m.isError = "Error reloading remotes: "+listErr.Error(), true
+	default:
+		m.message, m.isError = success, false
+	}
+	return m
+}
+
+// details lists the URLs and refspecs of the highlighted remote.
+func (m remoteModel) details() string {
+	remote, ok := m.current()
+	if !ok {
+		return ""
+	}
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Width(14)
+
+	pushURL := remote.PushURL
+	if pushURL == "" {
+		pushURL = remote.FetchURL
+	}
+	pushRefspecs := strings.Join(remote.PushRefspecs, ", ")
+	if pushRefspecs == "" {
+		pushRefspecs = "(git's push.default)"
+	}
+	rows := []string{
+		labelStyle.Render("Fetch URL") + remote.FetchURL,
+		labelStyle.Render("Push URL") + pushURL,
+		labelStyle.Render("Fetch") + strings.Join(remote.FetchRefspecs, ", "),
+		labelStyle.Render("Push") + pushRefspecs,
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("238")).
+		Padding(0, 1).
+		Render(strings.Join(rows, "\n"))
+}
+
+func (m remoteModel) View() string {
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	view := m.list.View()
+	if len(m.remotes) == 0 {
+		view = lipgloss.NewStyle().Padding(1, 2).Render("No remotes. Press a to add one.")
+	} else {
+		view += "\n" + m.details()
+	}
+
+	var status string
+	switch {
+	case m.prompt == remotePromptName:
+		status = "Add a remote named:\n" + m.input.View()
+	case m.prompt == remotePromptURL:
+		status = "URL of " + m.newName + ":\n" + m.input.View()
+	case m.prompt == remotePromptRename:
+		remote, _ := m.current()
+		status = "Rename " + remote.Name + " to:\n" + m.input.View()
+	case m.confirmRemove:
+		remote, _ := m.current()
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true).
+			Render("Remove " + remote.Name + " and its remote-tracking branches? y: remove • any other key: cancel")
+	case m.running != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Render(m.running + "...")
+		if m.progress != "" {
+			status += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(m.progress)
+		}
+	case m.message != "" && m.isError:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	case m.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	}
+	if status != "" {
+		view += "\n" + status
+	}
+
+	help := "↑↓: navigate • a: add • d: remove • r: rename • s: set default • f: fetch • F: fetch all • q: quit"
+	if m.prompt != remotePromptNone {
+		help = "enter: confirm • esc: cancel"
+	}
+	return view + "\n\n" + helpStyle.Render(help)
+}
+
+func init() {
+	rootCmd.AddCommand(remoteCmd)
+}
@@ -5,12 +5,45 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "smak",
 	Short: "A CLI tool for easier git interaction",
 	Long:  `Smak is a command-line tool that provides an interactive interface for common git operations including branch management and commit browsing.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		internal.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if !internal.DryRun {
+			return
+		}
+		// The summary goes to stderr so it never mixes with output meant for
+		// scripts, such as the path `smak w --pick` prints
+		out := cmd.ErrOrStderr()
+		skipped := internal.DryRunLog()
+		if len(skipped) == 0 {
+			fmt.Fprintln(out, "Dry run: no changes would be made")
+			return
+		}
+		fmt.Fprintln(out, "Dry run: nothing was changed. These commands were not executed:")
+		for _, line := range skipped {
+			fmt.Fprintln(out, line)
+		}
+	},
+}
+
+// dryRunCmd stands in for mutating git commands during a dry run and prints
+// what they would have done.
+var dryRunCmd = &cobra.Command{
+	Use:                internal.DryRunCommand,
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(internal.DescribeGitCommand(args))
+	},
 }
 
 func Execute() error {
@@ -18,15 +51,20 @@ func Execute() error {
 }
 
 func init() {
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Show the git commands that would change the repository instead of running them")
+	rootCmd.AddCommand(dryRunCmd)
 	rootCmd.SetHelpCommand(&cobra.Command{
 		Use:    "no-help",
 		Hidden: true,
 	})
 }
 
+// checkGitRepo makes sure smak runs inside a worktree (main or linked) and
+// moves to its top directory, since git reports paths relative to it.
 func checkGitRepo() error {
-	if _, err := os.Stat(".git"); os.IsNotExist(err) {
+	root, err := internal.RepositoryRoot()
+	if err != nil || root == "" {
 		return fmt.Errorf("not a git repository")
 	}
-	return nil
+	return os.Chdir(root)
 }
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+// stageFile is a changed file in the selective staging view. Files without
+// hunks (untracked, binary, mode changes) can only be staged as a whole.
+type stageFile struct {
+	entry        internal.StatusEntry
+	diff         internal.FileDiff
+	hunks        []internal.Hunk
+	selected     bool
+	hunkSelected map[int]bool
+	expanded     bool
+}
+
+// wholeFile reports whether the file is staged in one go rather than by hunk.
+func (f stageFile) wholeFile() bool {
+	return len(f.hunks) == 0 || f.entry.Untracked()
+}
+
+func (f stageFile) selectedHunks() []int {
+	var hunks []int
+	for i := range f.hunks {
+		if f.hunkSelected[i] {
+			hunks = append(hunks, i)
+		}
+	}
+	return hunks
+}
+
+// stageRow is a line in the view: a file, or one of its hunks when hunk is not -1.
+type stageRow struct {
+	file int
+	hunk int
+}
+
+// stageModel lets the user pick files and hunks to stage before amending or
+// committing them. action names what enter does with the picked changes.
+type stageModel struct {
+	title     string
+	action    string
+	files     []stageFile
+	staged    int
+	cursor    int
+	offset    int
+	width     int
+	height    int
+	confirmed bool
+}
+
+func newStageModel(title, action string) (stageModel, error) {
+	entries, err := internal.GetStatus()
+	if err != nil {
+		return stageModel{}, err
+	}
+
+	m := stageModel{title: title, action: action}
+	for _, entry := range entries {
+		if entry.Staged() {
+			m.staged++
+		}
+		if !entry.Unstaged() && !entry.Untracked() {
+			continue
+		}
+
+		file := stageFile{entry: entry, hunkSelected: make(map[int]bool)}
+		if diff, err := internal.GetWorktreeDiff(entry); err == nil {
+			if _, fileDiffs := internal.SplitDiff(diff); len(fileDiffs) > 0 {
+				file.diff = fileDiffs[0]
+				file.hunks = file.diff.Hunks()
+			}
+		}
+		m.files = append(m.files, file)
+	}
+
+	return m, nil
+}
+
+func (m stageModel) rows() []stageRow {
+	var rows []stageRow
+	for i, file := range m.files {
+		rows = append(rows, stageRow{file: i, hunk: -1})
+		if file.expanded && !file.wholeFile() {
+			for j := range file.hunks {
+				rows = append(rows, stageRow{file: i, hunk: j})
+			}
+		}
+	}
+	return rows
+}
+
+func (m stageModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m stageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		rows := m.rows()
+		if len(rows) == 0 {
+			switch msg.String() {
+			case "q", "esc", "ctrl+c", "enter":
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		row := rows[m.cursor]
+		file := &m.files[row.file]
+
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(rows)-1 {
+				m.cursor++
+			}
+		case "right", "l", "tab":
+			file.expanded = true
+		case "left", "h":
+			if file.expanded {
+				file.expanded = false
+				// Move the cursor back to the file row
+				for i, r := range m.rows() {
+					if r.file == row.file && r.hunk == -1 {
+						m.cursor = i
+					}
+				}
+			}
+		case " ":
+			switch {
+			case row.hunk >= 0:
+				file.hunkSelected[row.hunk] = !file.hunkSelected[row.hunk]
+			case file.wholeFile():
+				file.selected = !file.selected
+			default:
+				all := len(file.selectedHunks()) == len(file.hunks)
+				for i := range file.hunks {
+					file.hunkSelected[i] = !all
+				}
+			}
+		case "a":
+			for i := range m.files {
+				m.files[i].selected = true
+				for j := range m.files[i].hunks {
+					m.files[i].hunkSelected[j] = true
+				}
+			}
+		case "enter":
+			m.confirmed = true
+			return m, tea.Quit
+		}
+	}
+
+	return m, nil
+}
+
+// chooseAndStage runs the selective staging view and stages what the user
+// picked, with action describing what happens to it next. It reports false
+// when the user cancelled.
+func chooseAndStage(title, action string) (bool, error) {
+	m, err := newStageModel(title, action)
+	if err != nil {
+		return false, fmt.Errorf("reading status: %w", err)
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return false, fmt.Errorf("running program: %w", err)
+	}
+
+	m = final.(stageModel)
+	if !m.confirmed {
+		return false, nil
+	}
+	return true, m.stageSelection()
+}
+
+// stageSelection stages everything the user picked.
+func (m stageModel) stageSelection() error {
+	for _, file := range m.files {
+		hunks := file.selectedHunks()
+		switch {
+		case file.wholeFile() && file.selected, !file.wholeFile() && len(hunks) == len(file.hunks):
+			if err := internal.StageFile(file.entry.Path); err != nil {
+				return fmt.Errorf("staging %s: %w", file.entry.Path, err)
+			}
+		case !file.wholeFile() && len(hunks) > 0:
+			if err := internal.StageHunks(file.diff, hunks); err != nil {
+				return fmt.Errorf("staging hunks of %s: %w", file.entry.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m stageModel) View() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	title := titleStyle.Render(m.title)
+	if m.staged > 0 {
+		title += helpStyle.Render(fmt.Sprintf("  (%d file(s) already staged will be included)", m.staged))
+	}
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		return "\n  " + title + "\n\n  No unstaged changes.\n\n  " + helpStyle.Render("enter/q: quit") + "\n"
+	}
+
+	height := max(3, m.height-5)
+	m.offset = min(m.offset, m.cursor)
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+
+	listWidth := m.width
+	if m.width >= 100 {
+		listWidth = m.width / 2
+	}
+
+	var lines []string
+	for i := m.offset; i < min(len(rows), m.offset+height); i++ {
+		lines = append(lines, m.renderRow(rows[i], i == m.cursor, listWidth))
+	}
+	list := lipgloss.NewStyle().Width(listWidth).Height(height).Render(strings.Join(lines, "\n"))
+
+	body := list
+	if m.width >= 100 {
+		preview := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("238")).
+			Width(m.width - listWidth - 2).
+			Height(height - 2).
+			MaxHeight(height).
+			Render(m.renderPreview(rows[m.cursor], height-2))
+		body = lipgloss.JoinHorizontal(lipgloss.Top, list, preview)
+	}
+
+	help := "↑↓: navigate • space: toggle • →/←: expand/collapse hunks • a: select all • enter: stage & " + m.action + " • esc/q: cancel"
+	return title + "\n\n" + body + "\n\n" + helpStyle.Render(help)
+}
+
+func (m stageModel) renderRow(row stageRow, current bool, width int) string {
+	file := m.files[row.file]
+	cursor := "  "
+	if current {
+		cursor = "> "
+	}
+	nameStyle := lipgloss.NewStyle()
+	if current {
+		nameStyle = nameStyle.Foreground(lipgloss.Color("170"))
+	}
+
+	if row.hunk >= 0 {
+		hunk := file.hunks[row.hunk]
+		box := "[ ]"
+		if file.hunkSelected[row.hunk] {
+			box = "[x]"
+		}
+		added, removed := hunkCounts(hunk)
+		return cursor + "    " + box + " " + nameStyle.Render(truncate(hunk.Header, width-24)) + " " +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(fmt.Sprintf("+%d", added)) + " " +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(fmt.Sprintf("-%d", removed))
+	}
+
+	box := "[ ]"
+	switch selected := len(file.selectedHunks()); {
+	case file.wholeFile() && file.selected, !file.wholeFile() && selected == len(file.hunks):
+		box = "[x]"
+	case !file.wholeFile() && selected > 0:
+		box = "[~]"
+	}
+
+	marker := "  "
+	if !file.wholeFile() {
+		marker = "▸ "
+		if file.expanded {
+			marker = "▾ "
+		}
+	}
+
+	status := string(file.entry.Worktree)
+	if file.entry.Untracked() {
+		status = "?"
+	}
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+	if status == "?" {
+		statusStyle = statusStyle.Foreground(lipgloss.Color("46"))
+	} else if status == "D" {
+		statusStyle = statusStyle.Foreground(lipgloss.Color("196"))
+	}
+
+	hunks := ""
+	if !file.wholeFile() {
+		label := fmt.Sprintf(" (%d hunks)", len(file.hunks))
+		if len(file.hunks) == 1 {
+			label = " (1 hunk)"
+		}
+		hunks = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(label)
+	}
+
+	return cursor + marker + box + " " + statusStyle.Render(status) + " " + nameStyle.Render(truncateLeft(file.entry.Path, width-24)) + hunks
+}
+
+func (m stageModel) renderPreview(row stageRow, height int) string {
+	file := m.files[row.file]
+	lang := internal.LanguageForPath(file.entry.Path)
+
+	var lines []string
+	for i, hunk := range file.hunks {
+		if row.hunk >= 0 && i != row.hunk {
+			continue
+		}
+		lines = append(lines, colorDiff(hunk.Header))
+		lines = append(lines, renderUnifiedHunk(hunk, lang)...)
+		if len(lines) >= height {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("No preview (binary or mode change)"))
+	}
+	return strings.Join(lines[:min(len(lines), height)], "\n")
+}
+
+func hunkCounts(hunk internal.Hunk) (added, removed int) {
+	for _, line := range hunk.Lines {
+		switch line.Kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var stashCmd = &cobra.Command{
+	Use:   "st",
+	Short: "Browse and manage stashes",
+	Long:  `Interactive stash manager: preview stashed changes, apply, pop, drop, rename, turn a stash into a branch, or stash the current changes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		stashes, err := internal.GetStashes()
+		if err != nil {
+			fmt.Printf("Error getting stashes: %v\n", err)
+			return
+		}
+
+		model := newStashModel(stashes, splitDiffDefault(cmd))
+		p := tea.NewProgram(model, tea.WithAltScreen())
+
+		if _, err := p.Run(); err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+	},
+}
+
+type stashItem struct {
+	stash internal.Stash
+}
+
+func (i stashItem) Title() string {
+	return fmt.Sprintf("%s - %s", i.stash.Ref, i.stash.Message)
+}
+
+func (i stashItem) Description() string {
+	return fmt.Sprintf("%s | %s", i.stash.Branch, relativeTime(i.stash.Date))
+}
+
+func (i stashItem) FilterValue() string {
+	return i.stash.Message
+}
+
+// relativeTime renders how long ago something happened, e.g. "3 days ago".
+func relativeTime(t time.Time) string {
+	d := time.Since(t)
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	case d < 30*24*time.Hour:
+		return plural(int(d.Hours()/24), "day")
+	case d < 365*24*time.Hour:
+		return plural(int(d.Hours()/24/30), "month")
+	}
+	return plural(int(d.Hours()/24/365), "year")
+}
+
+// stashPrompt is the action a text prompt in the stash view is collecting input for.
+type stashPrompt int
+
+const (
+	promptNone stashPrompt = iota
+	promptRename
+	promptBranch
+	promptCreate
+)
+
+type stashModel struct {
+	list        list.Model
+	stashes     []internal.Stash
+	diff        diffView
+	showDiff    bool
+	helpVisible bool
+	width       int
+	height      int
+
+	confirmDrop bool
+	prompt      stashPrompt
+	input       textinput.Model
+	message     string
+	isError     bool
+
+	showResult      bool
+	result          *internal.MergeResult
+	resultOperation string
+}
+
+func newStashModel(stashes []internal.Stash, splitDiff bool) stashModel {
+	items := make([]list.Item, len(stashes))
+	for i, stash := range stashes {
+		items[i] = stashItem{stash: stash}
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Stashes"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	return stashModel{
+		list:        l,
+		stashes:     stashes,
+		diff:        newDiffView(splitDiff),
+		helpVisible: true,
+	}
+}
+
+func (m stashModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m stashModel) current() (internal.Stash, bool) {
+	if len(m.stashes) == 0 {
+		return internal.Stash{}, false
+	}
+	return m.stashes[m.list.Index()], true
+}
+
+func (m stashModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		helpHeight := 4
+		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		if m.showDiff {
+			m = m.layoutDiff()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.showResult {
+			if msg.String() == "enter" || msg.String() == "esc" {
+				m.showResult = false
+				return m.refresh(), nil
+			}
+			return m, nil
+		}
+
+		if m.prompt != promptNone {
+			return m.updatePrompt(msg)
+		}
+
+		if m.showDiff {
+			if !m.diff.searching {
+				switch msg.String() {
+				case "q", "ctrl+c", "esc":
+					m.showDiff = false
+					return m, nil
+				}
+			}
+			m.diff, _ = m.diff.update(msg)
+			return m.layoutDiff(), nil
+		}
+
+		if m.confirmDrop {
+			m.confirmDrop = false
+			if msg.String() == "y" {
+				if stash, ok := m.current(); ok {
+					return m.run(internal.DropStash(stash.Ref), "Dropped "+stash.Ref), nil
+				}
+			}
+			return m, nil
+		}
+
+		m.message = ""
+		stash, ok := m.current()
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "n":
+			return m.startPrompt(promptCreate, "", "message (optional)")
+		}
+		if !ok {
+			break
+		}
+
+		switch msg.String() {
+		case "enter":
+			raw, err := internal.GetStashDiff(stash.Ref)
+			if err != nil {
+				m.message, m.isError = "Error getting stash diff: "+err.Error(), true
+				return m, nil
+			}
+			m.diff = m.diff.setDiff(raw, nil)
+			m.showDiff = true
+			return m.layoutDiff(), nil
+		case "a", "p":
+			m.resultOperation = "Apply"
+			if msg.String() == "p" {
+				m.resultOperation = "Pop"
+			}
+			m.result = internal.ApplyStash(stash.Ref, msg.String() == "p")
+			m.showResult = true
+			return m, nil
+		case "d":
+			m.confirmDrop = true
+			return m, nil
+		case "r":
+			return m.startPrompt(promptRename, stash.Message, "new message")
+		case "b":
+			return m.startPrompt(promptBranch, "", "new branch name")
+		}
+	}
+
+	if m.prompt != promptNone {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	if !m.showDiff {
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m stashModel) startPrompt(prompt stashPrompt, value, placeholder string) (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.SetValue(value)
+	input.Width = max(20, m.width-10)
+	input.Focus()
+
+	m.prompt = prompt
+	m.input = input
+	return m, textinput.Blink
+}
+
+func (m stashModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.prompt = promptNone
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.input.Value())
+		prompt := m.prompt
+		m.prompt = promptNone
+
+		if prompt == promptCreate {
+			return m.run(internal.CreateStash(value), "Stashed all changes"), nil
+		}
+		stash, ok := m.current()
+		if !ok || value == "" {
+			return m, nil
+		}
+		if prompt == promptRename {
+			return m.run(internal.RenameStash(stash, value), "Renamed "+stash.Ref+" (now stash@{0})"), nil
+		}
+		return m.run(internal.StashBranch(value, stash.Ref), "Created branch "+value+" from "+stash.Ref), nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// run reports the outcome of an action and reloads the stash list.
+func (m stashModel) run(err error, success string) stashModel {
+	m = m.refresh()
+	if err != nil {
+		m.message, m.isError = err.Error(), true
+	} else {
+		m.message, m.isError = success, false
+	}
+	return m
+}
+
+func (m stashModel) refresh() stashModel {
+	stashes, err := internal.GetStashes()
+	if err != nil {
+		m.message, m.isError = "Error reloading stashes: "+err.Error(), true
+		return m
+	}
+
+	items := make([]list.Item, len(stashes))
+	for i, stash := range stashes {
+		items[i] = stashItem{stash: stash}
+	}
+	m.stashes = stashes
+	m.list.SetItems(items)
+	if m.list.Index() >= len(items) {
+		m.list.Select(max(0, len(items)-1))
+	}
+	return m
+}
+
+func (m stashModel) layoutDiff() stashModel {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = 100, 40
+	}
+	m.diff = m.diff.setSize(width, height-4)
+	return m
+}
+
+func (m stashModel) View() string {
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	if m.showResult {
+		stash, _ := m.current()
+		helpText := "enter: continue"
+		if m.result.HasConflicts {
+			helpText = "resolve the conflicts in your working tree • enter: continue"
+			if m.resultOperation == "Pop" {
+				helpText = "the stash was kept because of the conflicts • " + helpText
+			}
+		}
+		return renderResultPanel(m.resultOperation+" "+stash.Ref, m.resultOperation, m.result, helpText)
+	}
+
+	if m.showDiff {
+		stash, _ := m.current()
+		title := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true).Render(stash.Ref + " " + stash.Message)
+		return lipgloss.JoinVertical(lipgloss.Left,
+			title+"  "+m.diff.stat(),
+			m.diff.View(),
+			"",
+			helpStyle.Width(m.width).Render(m.diff.helpText()+" • esc: back"),
+		)
+	}
+
+	view := m.list.View()
+	if len(m.stashes) == 0 {
+		view = lipgloss.NewStyle().Padding(1, 2).Render("No stashes. Press n to stash your current changes.")
+	}
+
+	var status string
+	switch {
+	case m.prompt != promptNone:
+		label := map[stashPrompt]string{
+			promptRename: "Rename stash: ",
+			promptBranch: "Branch from stash: ",
+			promptCreate: "Stash all changes (including untracked) with message: ",
+		}[m.prompt]
+		status = label + "\n" + m.input.View()
+	case m.confirmDrop:
+		stash, _ := m.current()
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true).
+			Render("Drop " + stash.Ref + " (" + stash.Message + ")? y: drop • any other key: cancel")
+	case m.message != "" && m.isError:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	case m.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	}
+	if status != "" {
+		view += "\n" + status
+	}
+
+	if m.helpVisible {
+		help := "↑↓: navigate • enter: diff • a: apply • p: pop • d: drop • r: rename • b: branch • n: new stash • q: quit"
+		if m.prompt != promptNone {
+			help = "enter: confirm • esc: cancel"
+		}
+		view += "\n\n" + helpStyle.Render(help)
+	}
+
+	return view
+}
+
+func init() {
+	stashCmd.Flags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
+	rootCmd.AddCommand(stashCmd)
+}
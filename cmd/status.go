@@ -0,0 +1,658 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "s",
+	Short: "Show the working tree and stage, discard or commit changes",
+	Long:  `Interactive status view listing conflicted, staged, unstaged and untracked files, with per-file and per-hunk staging, unstaging and discarding, a diff preview and a commit editor.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		model, err := newStatusModel(splitDiffDefault(cmd))
+		if err != nil {
+			fmt.Printf("Error reading status: %v\n", err)
+			return
+		}
+
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+	},
+}
+
+type statusSection int
+
+const (
+	sectionConflicted statusSection = iota
+	sectionStaged
+	sectionUnstaged
+	sectionUntracked
+)
+
+var statusSectionTitles = map[statusSection]string{
+	sectionConflicted: "Unmerged paths",
+	sectionStaged:     "Staged changes",
+	sectionUnstaged:   "Unstaged changes",
+	sectionUntracked:  "Untracked files",
+}
+
+// statusItem is a path in one section of the status view. A file with both
+// staged and unstaged changes shows up in both sections.
+type statusItem struct {
+	section  statusSection
+	entry    internal.StatusEntry
+	loaded   bool
+	raw      string
+	diff     internal.FileDiff
+	hunks    []internal.Hunk
+	expanded bool
+}
+
+func (i statusItem) key() string {
+	return fmt.Sprintf("%d:%s", i.section, i.entry.Path)
+}
+
+// load reads the item's diff the first time it is needed.
+func (i *statusItem) load() {
+	if i.loaded {
+		return
+	}
+	i.loaded = true
+
+	var raw string
+	var err error
+	if i.section == sectionStaged {
+		raw, err = internal.GetStagedDiff(i.entry)
+	} else {
+		raw, err = internal.GetWorktreeDiff(i.entry)
+	}
+	if err != nil {
+		log.Printf("Error getting diff for %s: %v", i.entry.Path, err)
+		return
+	}
+
+	i.raw = raw
+	if _, fileDiffs := internal.SplitDiff(raw); len(fileDiffs) > 0 {
+		i.diff = fileDiffs[0]
+		// Conflicts and new files are handled as a whole
+		if i.section == sectionStaged || i.section == sectionUnstaged {
+			i.hunks = i.diff.Hunks()
+		}
+	}
+}
+
+type statusRow struct {
+	item int
+	hunk int
+}
+
+type statusModel struct {
+	items   []statusItem
+	branch  string
+	cursor  int
+	preview diffView
+	// previewRow is the row the preview was last loaded for
+	previewRow statusRow
+	showDiff   bool
+	width      int
+	height     int
+
+	confirmDiscard bool
+	message        string
+	messageIsError bool
+
+	committing  bool
+	commitInput textarea.Model
+	commitError string
+}
+
+func newStatusModel(split bool) (statusModel, error) {
+	m := statusModel{
+		preview:    newDiffView(split),
+		previewRow: statusRow{item: -1},
+	}
+	m.preview.hideFiles = true
+	return m.reload()
+}
+
+// reload reads the status again, keeping expanded files expanded and the
+// cursor on the same file where possible.
+func (m statusModel) reload() (statusModel, error) {
+	entries, err := internal.GetStatus()
+	if err != nil {
+		return m, err
+	}
+
+	expanded := make(map[string]bool)
+	for _, item := range m.items {
+		if item.expanded {
+			expanded[item.key()] = true
+		}
+	}
+	current := ""
+	if row, ok := m.currentRow(); ok {
+		current = m.items[row.item].key()
+	}
+
+	var sections [4][]statusItem
+	for _, entry := range entries {
+		switch {
+		case entry.Conflicted():
+			sections[sectionConflicted] = append(sections[sectionConflicted], statusItem{section: sectionConflicted, entry: entry})
+		case entry.Untracked():
+			sections[sectionUntracked] = append(sections[sectionUntracked], statusItem{section: sectionUntracked, entry: entry})
+		default:
+			if entry.Staged() {
+				sections[sectionStaged] = append(sections[sectionStaged], statusItem{section: sectionStaged, entry: entry})
+			}
+			if entry.Unstaged() {
+				sections[sectionUnstaged] = append(sections[sectionUnstaged], statusItem{section: sectionUnstaged, entry: entry})
+			}
+		}
+	}
+
+	m.items = nil
+	for _, section := range sections {
+		m.items = append(m.items, section...)
+	}
+	for i := range m.items {
+		if expanded[m.items[i].key()] {
+			m.items[i].load()
+			m.items[i].expanded = len(m.items[i].hunks) > 0
+		}
+	}
+
+	m.branch, err = internal.CurrentBranch()
+	if err != nil {
+		m.branch = "detached HEAD"
+	}
+
+	rows := m.rows()
+	for i, row := range rows {
+		if row.hunk == -1 && m.items[row.item].key() == current {
+			m.cursor = i
+		}
+	}
+	m.cursor = max(0, min(m.cursor, len(rows)-1))
+	m.previewRow = statusRow{item: -1}
+	return m.updatePreview(), nil
+}
+
+func (m statusModel) rows() []statusRow {
+	var rows []statusRow
+	for i, item := range m.items {
+		rows = append(rows, statusRow{item: i, hunk: -1})
+		if item.expanded {
+			for j := range item.hunks {
+				rows = append(rows, statusRow{item: i, hunk: j})
+			}
+		}
+	}
+	return rows
+}
+
+func (m statusModel) currentRow() (statusRow, bool) {
+	rows := m.rows()
+	if m.cursor >= len(rows) {
+		return statusRow{}, false
+	}
+	return rows[m.cursor], true
+}
+
+// updatePreview loads the diff of the current file into the preview and
+// scrolls it to the current hunk.
+func (m statusModel) updatePreview() statusModel {
+	row, ok := m.currentRow()
+	if !ok {
+		m.preview = m.preview.setDiff("", nil)
+		return m
+	}
+	if row == m.previewRow {
+		return m
+	}
+
+	item := &m.items[row.item]
+	if row.item != m.previewRow.item {
+		item.load()
+		m.preview = m.preview.setDiff(item.raw, nil)
+	}
+	if row.hunk >= 0 && row.hunk < len(m.preview.hunkOffsets) {
+		m.preview.viewport.SetYOffset(m.preview.hunkOffsets[row.hunk])
+	} else {
+		m.preview.viewport.GotoTop()
+	}
+	m.previewRow = row
+	return m
+}
+
+func (m statusModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.committing {
+			m.commitInput.SetWidth(max(20, m.width-6))
+		}
+		return m.layout(), nil
+
+	case tea.KeyMsg:
+		if m.committing {
+			return m.updateCommit(msg)
+		}
+
+		if m.showDiff {
+			if !m.preview.searching {
+				switch msg.String() {
+				case "q", "esc":
+					m.showDiff = false
+					return m.layout(), nil
+				}
+			}
+			m.preview, _ = m.preview.update(msg)
+			return m, nil
+		}
+
+		if m.confirmDiscard {
+			m.confirmDiscard = false
+			if msg.String() == "y" {
+				return m.discard(), nil
+			}
+			m.message = ""
+			return m, nil
+		}
+
+		m.message = ""
+		rows := m.rows()
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(rows)-1 {
+				m.cursor++
+			}
+		case "right", "l":
+			if row, ok := m.currentRow(); ok {
+				item := &m.items[row.item]
+				item.load()
+				item.expanded = len(item.hunks) > 0
+			}
+		case "left", "h":
+			if row, ok := m.currentRow(); ok && m.items[row.item].expanded {
+				m.items[row.item].expanded = false
+				for i, r := range m.rows() {
+					if r.item == row.item && r.hunk == -1 {
+						m.cursor = i
+					}
+				}
+			}
+		case " ":
+			return m.toggleStaged(), nil
+		case "a":
+			return m.run(internal.StageFile(":/"), "Staged all changes"), nil
+		case "u":
+			return m.run(internal.UnstageAll(), "Unstaged all changes"), nil
+		case "d":
+			if row, ok := m.currentRow(); ok {
+				switch m.items[row.item].section {
+				case sectionStaged:
+					m.message, m.messageIsError = "Unstage the changes before discarding them", true
+				case sectionConflicted:
+					m.message, m.messageIsError = "Resolve or stage conflicted files instead of discarding them", true
+				default:
+					m.confirmDiscard = true
+				}
+			}
+			return m, nil
+		case "c":
+			return m.startCommit()
+		case "r":
+			return m.run(nil, "Refreshed"), nil
+		case "enter":
+			if _, ok := m.currentRow(); ok {
+				m.showDiff = true
+				return m.layout(), nil
+			}
+		}
+		return m.updatePreview(), nil
+	}
+
+	if m.committing {
+		var cmd tea.Cmd
+		m.commitInput, cmd = m.commitInput.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// run reports the outcome of an action and reloads the status.
+func (m statusModel) run(err error, success string) statusModel {
+	if err != nil {
+		m.message, m.messageIsError = err.Error(), true
+	} else {
+		m.message, m.messageIsError = success, false
+	}
+
+	reloaded, reloadErr := m.reload()
+	if reloadErr != nil {
+		m.message, m.messageIsError = "Error reading status: "+reloadErr.Error(), true
+		return m
+	}
+	return reloaded
+}
+
+// toggleStaged stages the current file or hunk, or unstages it when it is
+// in the staged section.
+func (m statusModel) toggleStaged() statusModel {
+	row, ok := m.currentRow()
+	if !ok {
+		return m
+	}
+	item := m.items[row.item]
+
+	if item.section == sectionStaged {
+		if row.hunk >= 0 {
+			return m.run(internal.UnstageHunks(item.diff, []int{row.hunk}), "Unstaged hunk of "+item.entry.Path)
+		}
+		return m.run(internal.UnstageFile(item.entry), "Unstaged "+item.entry.Path)
+	}
+
+	if row.hunk >= 0 {
+		return m.run(internal.StageHunks(item.diff, []int{row.hunk}), "Staged hunk of "+item.entry.Path)
+	}
+	if item.section == sectionConflicted {
+		return m.run(internal.StageFile(item.entry.Path), "Marked "+item.entry.Path+" as resolved")
+	}
+	return m.run(internal.StageFile(item.entry.Path), "Staged "+item.entry.Path)
+}
+
+func (m statusModel) discard() statusModel {
+	row, ok := m.currentRow()
+	if !ok {
+		return m
+	}
+	item := m.items[row.item]
+
+	if row.hunk >= 0 {
+		return m.run(internal.DiscardHunks(item.diff, []int{row.hunk}), "Discarded hunk of "+item.entry.Path)
+	}
+	if item.entry.Untracked() {
+		return m.run(internal.DiscardFile(item.entry), "Deleted "+item.entry.Path)
+	}
+	return m.run(internal.DiscardFile(item.entry), "Discarded changes to "+item.entry.Path)
+}
+
+func (m statusModel) startCommit() (tea.Model, tea.Cmd) {
+	staged := false
+	for _, item := range m.items {
+		staged = staged || item.section == sectionStaged
+	}
+	if !staged {
+		m.message, m.messageIsError = "Nothing staged to commit", true
+		return m, nil
+	}
+
+	input := textarea.New()
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.Placeholder = "Commit message"
+	input.SetWidth(max(20, m.width-6))
+	input.SetHeight(max(5, min(15, m.height-10)))
+	input.Focus()
+
+	m.committing = true
+	m.commitInput = input
+	m.commitError = ""
+	return m, textarea.Blink
+}
+
+func (m statusModel) updateCommit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.committing = false
+		return m, nil
+	case "ctrl+s":
+		message := strings.TrimSpace(m.commitInput.Value())
+		if message == "" {
+			m.commitError = "The commit message cannot be empty"
+			return m, nil
+		}
+		if err := internal.CreateCommit(message); err != nil {
+			m.commitError = err.Error()
+			return m, nil
+		}
+		m.committing = false
+		subject, _, _ := strings.Cut(message, "\n")
+		return m.run(nil, "Committed: "+subject), nil
+	}
+
+	var cmd tea.Cmd
+	m.commitInput, cmd = m.commitInput.Update(msg)
+	return m, cmd
+}
+
+func (m statusModel) listWidth() int {
+	if m.width >= 100 {
+		return m.width * 2 / 5
+	}
+	return m.width
+}
+
+// bodyHeight is what is left for the file list after the title, status and help lines.
+func (m statusModel) bodyHeight() int {
+	return max(3, m.height-7)
+}
+
+func (m statusModel) layout() statusModel {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = 100, 40
+	}
+
+	if m.showDiff {
+		m.preview = m.preview.setSize(width, height-3)
+	} else {
+		m.preview = m.preview.setSize(width-m.listWidth(), m.bodyHeight())
+	}
+	return m
+}
+
+func (m statusModel) View() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	if m.committing {
+		return m.renderCommit()
+	}
+
+	if m.showDiff {
+		row, _ := m.currentRow()
+		item := m.items[row.item]
+		title := titleStyle.Render(statusSectionTitles[item.section] + ": " + item.entry.Path)
+		return lipgloss.JoinVertical(lipgloss.Left,
+			title,
+			m.preview.View(),
+			"",
+			helpStyle.Width(m.width).Render(m.preview.helpText()+" • esc: back"),
+		)
+	}
+
+	title := titleStyle.Render("Status") + lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Render(" on "+m.branch)
+
+	if len(m.items) == 0 {
+		return title + "\n\n" + "Nothing to commit, working tree clean" + "\n\n" + helpStyle.Render("r: refresh • q: quit")
+	}
+
+	body := m.renderList()
+	if m.width >= 100 {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, body, m.preview.View())
+	}
+
+	status := ""
+	switch {
+	case m.confirmDiscard:
+		row, _ := m.currentRow()
+		target := m.items[row.item].entry.Path
+		if row.hunk >= 0 {
+			target = "this hunk of " + target
+		}
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true).
+			Render("Discard " + target + "? This cannot be undone. y: discard • any other key: cancel")
+	case m.message != "" && m.messageIsError:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	case m.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	}
+
+	help := "↑↓: navigate • space: stage/unstage • →/←: hunks • d: discard • a/u: stage/unstage all • c: commit • enter: diff • q: quit"
+	return title + "\n\n" + body + "\n" + status + "\n" + helpStyle.Width(m.width).Render(help)
+}
+
+func (m statusModel) renderList() string {
+	width := m.listWidth()
+	height := m.bodyHeight()
+	sectionStyle := lipgloss.NewStyle().Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+
+	counts := make(map[statusSection]int)
+	for _, item := range m.items {
+		counts[item.section]++
+	}
+
+	var lines []string
+	cursorLine := 0
+	lastSection := statusSection(-1)
+	for i, row := range m.rows() {
+		item := m.items[row.item]
+		if item.section != lastSection {
+			if lastSection != -1 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, sectionStyle.Render(statusSectionTitles[item.section])+dimStyle.Render(fmt.Sprintf(" (%d)", counts[item.section])))
+			lastSection = item.section
+		}
+		if i == m.cursor {
+			cursorLine = len(lines)
+		}
+		lines = append(lines, m.renderRow(row, i == m.cursor, width))
+	}
+
+	// Keep the cursor in view
+	start := 0
+	if cursorLine >= height {
+		start = cursorLine - height + 1
+	}
+	end := min(len(lines), start+height)
+
+	return lipgloss.NewStyle().Width(width).Height(height).Render(strings.Join(lines[start:end], "\n"))
+}
+
+func (m statusModel) renderRow(row statusRow, current bool, width int) string {
+	item := m.items[row.item]
+	cursor := "  "
+	nameStyle := lipgloss.NewStyle()
+	if current {
+		cursor = "> "
+		nameStyle = nameStyle.Foreground(lipgloss.Color("170"))
+	}
+
+	if row.hunk >= 0 {
+		hunk := item.hunks[row.hunk]
+		added, removed := hunkCounts(hunk)
+		return cursor + "    " + nameStyle.Render(truncate(hunk.Header, width-20)) + " " +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(fmt.Sprintf("+%d", added)) + " " +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(fmt.Sprintf("-%d", removed))
+	}
+
+	var status string
+	var color lipgloss.Color
+	switch item.section {
+	case sectionConflicted:
+		status, color = string(item.entry.Index)+string(item.entry.Worktree), "196"
+	case sectionStaged:
+		status, color = string(item.entry.Index), "46"
+	case sectionUnstaged:
+		status, color = string(item.entry.Worktree), "208"
+	default:
+		status, color = "?", "243"
+	}
+
+	marker := "  "
+	hunkable := item.section == sectionStaged || item.section == sectionUnstaged
+	if hunkable && (!item.loaded || len(item.hunks) > 0) {
+		marker = "▸ "
+		if item.expanded {
+			marker = "▾ "
+		}
+	}
+
+	path := item.entry.Path
+	if item.entry.OldPath != "" && item.section == sectionStaged {
+		path = item.entry.OldPath + " → " + path
+	}
+
+	return cursor + marker + lipgloss.NewStyle().Foreground(color).Width(2).Render(status) + " " +
+		nameStyle.Render(truncateLeft(path, width-10))
+}
+
+func (m statusModel) renderCommit() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	fileStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	var staged []string
+	for _, item := range m.items {
+		if item.section == sectionStaged {
+			staged = append(staged, "  "+fileStyle.Render(string(item.entry.Index)+" "+item.entry.Path))
+		}
+	}
+	const maxFiles = 8
+	if len(staged) > maxFiles {
+		staged = append(staged[:maxFiles], helpStyle.Render(fmt.Sprintf("  … and %d more", len(staged)-maxFiles)))
+	}
+
+	lines := []string{
+		titleStyle.Render("Commit to " + m.branch),
+		"",
+		m.commitInput.View(),
+		"",
+	}
+	lines = append(lines, staged...)
+	lines = append(lines, "")
+	if m.commitError != "" {
+		lines = append(lines, errorStyle.Render(m.commitError))
+	}
+	lines = append(lines, helpStyle.Render("ctrl+s: commit • esc: cancel"))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func init() {
+	statusCmd.Flags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch all remotes and fast-forward local branches",
+	Long: `Fetches every remote, pruning remote-tracking branches that were deleted, then
+fast-forwards each local branch that is behind its upstream and has no commits
+of its own. Branches are not checked out: the current branch and branches used
+by other worktrees are merged there, the rest only have their ref moved.
+
+Prints a table of what moved, what diverged and what has no upstream.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		if noFetch, _ := cmd.Flags().GetBool("no-fetch"); !noFetch {
+			fmt.Println("Fetching all remotes...")
+			if _, err := internal.FetchAll(); err != nil {
+				fmt.Printf("Error fetching: %v\n", err)
+				return
+			}
+		}
+
+		results, err := internal.SyncBranches()
+		if err != nil {
+			fmt.Printf("Error syncing branches: %v\n", err)
+			return
+		}
+		if len(results) == 0 {
+			fmt.Println("No local branches")
+			return
+		}
+		fmt.Println(renderSyncTable(results))
+	},
+}
+
+// syncStatusColors colours each outcome: green for moved, orange for
+// branches that need attention and dim for the rest.
+var syncStatusColors = map[string]string{
+	internal.SyncFastForwarded: "46",
+	internal.SyncUpToDate:      "243",
+	internal.SyncAhead:         "243",
+	internal.SyncNoUpstream:    "243",
+	internal.SyncDiverged:      "208",
+	internal.SyncUpstreamGone:  "208",
+	internal.SyncFailed:        "196",
+}
+
+func syncDetails(result internal.SyncResult) string {
+	switch result.Status {
+	case internal.SyncFastForwarded:
+		return fmt.Sprintf("%s → %s (%d new)", shortHash(result.From), shortHash(result.To), result.Behind)
+	case internal.SyncAhead:
+		return fmt.Sprintf("%d to push", result.Ahead)
+	case internal.SyncDiverged:
+		return fmt.Sprintf("%d ahead, %d behind; rebase or merge it", result.Ahead, result.Behind)
+	case internal.SyncUpstreamGone:
+		return "deleted on the remote"
+	case internal.SyncFailed:
+		return result.Err.Error()
+	}
+	return ""
+}
+
+func renderSyncTable(results []internal.SyncResult) string {
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+
+	rows := [][]string{{"BRANCH", "UPSTREAM", "RESULT", "DETAILS"}}
+	for _, result := range results {
+		upstream := result.Upstream
+		if upstream == "" {
+			upstream = "-"
+		}
+		rows = append(rows, []string{result.Branch, upstream, result.Status, syncDetails(result)})
+	}
+
+	widths := make([]int, 3)
+	for _, row := range rows {
+		for i := range widths {
+			widths[i] = max(widths[i], lipgloss.Width(row[i]))
+		}
+	}
+
+	counts := make(map[string]int)
+	lines := make([]string, 0, len(rows)+2)
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			if j < len(widths) {
+				cell += strings.Repeat(" ", widths[j]-lipgloss.Width(cell))
+			}
+			cells[j] = cell
+		}
+
+		if i == 0 {
+			lines = append(lines, headerStyle.Render(strings.Join(cells, "  ")))
+			continue
+		}
+		status := results[i-1].Status
+		counts[status]++
+		statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(syncStatusColors[status]))
+		lines = append(lines, cells[0]+"  "+dimStyle.Render(cells[1])+"  "+statusStyle.Render(cells[2])+"  "+dimStyle.Render(cells[3]))
+	}
+
+	var summary []string
+	for _, status := range []string{internal.SyncFastForwarded, internal.SyncDiverged, internal.SyncUpstreamGone, internal.SyncFailed} {
+		if counts[status] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	if len(summary) == 0 {
+		summary = append(summary, "nothing to fast-forward")
+	}
+	lines = append(lines, "", strings.Join(summary, ", "))
+
+	return strings.Join(lines, "\n")
+}
+
+func init() {
+	syncCmd.Flags().Bool("no-fetch", false, "Only fast-forward to the remote-tracking branches already fetched")
+	rootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,650 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "t",
+	Short: "Browse, create, push and delete tags",
+	Long: `Interactive tag manager: browse lightweight and annotated tags sorted by
+version or date, inspect the tagged commit, create annotated or signed tags
+with semver bump suggestions, push tags and delete them locally or on a remote.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		sortBy, _ := cmd.Flags().GetString("sort")
+		if sortBy != internal.TagSortVersion && sortBy != internal.TagSortDate {
+			fmt.Printf("Error: --sort must be %q or %q\n", internal.TagSortVersion, internal.TagSortDate)
+			return
+		}
+		remote, _ := cmd.Flags().GetString("remote")
+		if remote == "" {
+			remote = internal.DefaultRemote()
+		}
+
+		tags, err := internal.GetTags(sortBy)
+		if err != nil {
+			fmt.Printf("Error getting tags: %v\n", err)
+			return
+		}
+
+		model := newTagModel(tags, sortBy, remote, splitDiffDefault(cmd))
+		p := tea.NewProgram(model, tea.WithAltScreen())
+
+		if _, err := p.Run(); err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+	},
+}
+
+type tagItem struct {
+	tag      internal.Tag
+	selected bool
+}
+
+func (i tagItem) Title() string {
+	return i.tag.Name
+}
+
+func (i tagItem) Description() string {
+	kind := "lightweight"
+	if i.tag.Signed {
+		kind = "signed"
+	} else if i.tag.Annotated {
+		kind = "annotated"
+	}
+	return fmt.Sprintf("%s | %s | %s %s", kind, relativeTime(i.tag.Date), shortHash(i.tag.Commit), i.tag.CommitSubject)
+}
+
+func (i tagItem) FilterValue() string {
+	return i.tag.Name
+}
+
+type tagDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d tagDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item, ok := listItem.(tagItem)
+	if !ok {
+		d.DefaultDelegate.Render(w, m, index, listItem)
+		return
+	}
+
+	var titleStyle, descStyle lipgloss.Style
+	if item.selected {
+		// Orange for tags selected to push or delete
+		titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+		descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+		if index == m.Index() {
+			titleStyle = titleStyle.Bold(true).Underline(true)
+		}
+	} else if index == m.Index() {
+		titleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+		descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	} else {
+		titleStyle = lipgloss.NewStyle()
+		descStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	}
+
+	width := m.Width() - 2
+	fmt.Fprint(w, "  "+titleStyle.Render(truncate(item.Title(), width)))
+	fmt.Fprint(w, "\n")
+	fmt.Fprint(w, "  "+descStyle.Render(truncate(item.Description(), width)))
+}
+
+type tagField int
+
+const (
+	tagFieldVersion tagField = iota
+	tagFieldName
+	tagFieldCommit
+	tagFieldMessage
+	tagFieldSign
+	tagFieldCount
+)
+
+var tagFieldLabels = map[tagField]string{
+	tagFieldVersion: "Bump",
+	tagFieldName:    "Name",
+	tagFieldCommit:  "Commit",
+	tagFieldMessage: "Message",
+	tagFieldSign:    "Sign",
+}
+
+// tagForm collects the options of a new tag.
+type tagForm struct {
+	latest      internal.Version
+	hasLatest   bool
+	bump        int
+	name        textinput.Model
+	commit      textinput.Model
+	message     textinput.Model
+	sign        bool
+	focus       tagField
+	commitInfo  string
+	commitError bool
+	err         string
+}
+
+type tagModel struct {
+	list     list.Model
+	tags     []internal.Tag
+	selected map[string]bool
+	sortBy   string
+	remote   string
+	width    int
+	height   int
+
+	showDetails bool
+	details     internal.Tag
+	diff        diffView
+
+	confirmDelete bool
+	creating      bool
+	form          tagForm
+
+	message string
+	isError bool
+}
+
+func newTagModel(tags []internal.Tag, sortBy, remote string, splitDiff bool) tagModel {
+	l := list.New(nil, tagDelegate{DefaultDelegate: list.NewDefaultDelegate()}, 0, 0)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	m := tagModel{
+		list:     l,
+		tags:     tags,
+		selected: make(map[string]bool),
+		sortBy:   sortBy,
+		remote:   remote,
+		diff:     newDiffView(splitDiff),
+	}
+	return m.updateListItems()
+}
+
+func (m tagModel) updateListItems() tagModel {
+	items := make([]list.Item, len(m.tags))
+	for i, tag := range m.tags {
+		items[i] = tagItem{tag: tag, selected: m.selected[tag.Name]}
+	}
+	m.list.SetItems(items)
+	m.list.Title = "Tags (by " + m.sortBy + ")"
+	if m.list.Index() >= len(items) {
+		m.list.Select(max(0, len(items)-1))
+	}
+	return m
+}
+
+// actionNames returns the selected tags, or the highlighted one when
+// nothing is selected.
+func (m tagModel) actionNames() []string {
+	var names []string
+	for _, tag := range m.tags {
+		if m.selected[tag.Name] {
+			names = append(names, tag.Name)
+		}
+	}
+	if len(names) == 0 && len(m.tags) > 0 {
+		names = []string{m.tags[m.list.Index()].Name}
+	}
+	return names
+}
+
+func (m tagModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m tagModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		helpHeight := 4
+		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		if m.showDetails {
+			m = m.layoutDiff()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.creating {
+			return m.updateForm(msg)
+		}
+
+		if m.showDetails {
+			if !m.diff.searching {
+				switch msg.String() {
+				case "q", "ctrl+c":
+					return m, tea.Quit
+				case "esc":
+					m.showDetails = false
+					return m, nil
+				}
+			}
+			m.diff, _ = m.diff.update(msg)
+			return m.layoutDiff(), nil
+		}
+
+		if m.confirmDelete {
+			m.confirmDelete = false
+			switch msg.String() {
+			case "y":
+				return m.deleteTags(false), nil
+			case "r":
+				return m.deleteTags(true), nil
+			}
+			return m, nil
+		}
+
+		m.message = ""
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "n":
+			return m.startForm()
+		case "s":
+			if m.sortBy == internal.TagSortVersion {
+				m.sortBy = internal.TagSortDate
+			} else {
+				m.sortBy = internal.TagSortVersion
+			}
+			return m.refresh(), nil
+		}
+		if len(m.tags) == 0 {
+			break
+		}
+
+		switch msg.String() {
+		case " ":
+			name := m.tags[m.list.Index()].Name
+			if m.selected[name] {
+				delete(m.selected, name)
+			} else {
+				m.selected[name] = true
+			}
+			m = m.updateListItems()
+			m.list.CursorDown()
+			return m, nil
+		case "enter":
+			return m.openTag(m.tags[m.list.Index()]), nil
+		case "d":
+			m.confirmDelete = true
+			return m, nil
+		case "p":
+			names := m.actionNames()
+			if err := internal.PushTags(m.remote, names); err != nil {
+				m.message, m.isError = "Error pushing tags: "+err.Error(), true
+				return m, nil
+			}
+			m.message, m.isError = fmt.Sprintf("Pushed %s to %s", strings.Join(names, ", "), m.remote), false
+			m.selected = make(map[string]bool)
+			return m.updateListItems(), nil
+		}
+	}
+
+	if !m.showDetails {
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// deleteTags deletes the tags being acted on, first from the remote when
+// asked to so a failed remote deletion leaves the local tags in place.
+func (m tagModel) deleteTags(remote bool) tagModel {
+	names := m.actionNames()
+	if remote {
+		if err := internal.DeleteRemoteTags(m.remote, names); err != nil {
+			m.message, m.isError = "Error deleting tags on "+m.remote+": "+err.Error(), true
+			return m
+		}
+	}
+	if err := internal.DeleteTags(names); err != nil {
+		m.message, m.isError = "Error deleting tags: "+err.Error(), true
+		return m.refresh()
+	}
+
+	m.selected = make(map[string]bool)
+	m = m.refresh()
+	m.message, m.isError = "Deleted "+strings.Join(names, ", "), false
+	if remote {
+		m.message += " locally and on " + m.remote
+	}
+	return m
+}
+
+func (m tagModel) refresh() tagModel {
+	tags, err := internal.GetTags(m.sortBy)
+	if err != nil {
+		m.message, m.isError = "Error reloading tags: "+err.Error(), true
+		return m
+	}
+	m.tags = tags
+	return m.updateListItems()
+}
+
+// openTag loads the tagged commit's diff and switches to the details view.
+func (m tagModel) openTag(tag internal.Tag) tagModel {
+	diff, err := internal.GetCommitDiff(tag.Commit)
+	if err != nil {
+		m.message, m.isError = "Error getting diff: "+err.Error(), true
+		return m
+	}
+	files, err := internal.GetCommitFiles(tag.Commit)
+	if err != nil {
+		log.Printf("Error getting changed files: %v", err)
+	}
+
+	m.details = tag
+	m.diff = m.diff.setDiff(diff, files)
+	m.showDetails = true
+	return m.layoutDiff()
+}
+
+func (m tagModel) detailsHeader() string {
+	tag := m.details
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Width(9)
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+	field := func(label, value string) string {
+		return labelStyle.Render(label) + valueStyle.Render(value)
+	}
+
+	lines := []string{lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true).Render("Tag: " + tag.Name)}
+	if tag.Annotated {
+		lines = append(lines, field("Tagger:", fmt.Sprintf("%s <%s>  %s", tag.Tagger, tag.TaggerEmail, tag.Date.Format("2006-01-02 15:04:05"))))
+		if tag.Signed {
+			lines = append(lines, field("Signed:", "yes (verify with git tag -v "+tag.Name+")"))
+		}
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Bold(true).Render(tag.Subject))
+		if tag.Body != "" {
+			body := strings.Split(tag.Body, "\n")
+			if len(body) > maxBodyLines {
+				hidden := len(body) - maxBodyLines
+				body = append(body[:maxBodyLines], lipgloss.NewStyle().Foreground(lipgloss.Color("241")).
+					Render(fmt.Sprintf("… %d more lines", hidden)))
+			}
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("252")).PaddingLeft(2).Render(strings.Join(body, "\n")))
+		}
+		lines = append(lines, "")
+	} else {
+		lines = append(lines, field("Type:", "lightweight"))
+	}
+
+	lines = append(lines, field("Commit:", tag.Commit+"  "+tag.CommitSubject), "", m.diff.stat())
+	if status := m.diff.searchStatus(); status != "" {
+		lines = append(lines, status)
+	}
+	lines = append(lines, "")
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m tagModel) diffHelp() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	if m.width > 0 {
+		style = style.Width(m.width)
+	}
+	return style.Render(m.diff.helpText() + " • esc: back • q: quit")
+}
+
+func (m tagModel) layoutDiff() tagModel {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = 100, 40
+	}
+
+	helpHeight := lipgloss.Height(m.diffHelp()) + 1
+	m.diff = m.diff.setSize(width, height-lipgloss.Height(m.detailsHeader())-helpHeight)
+	return m
+}
+
+func (m tagModel) startForm() (tea.Model, tea.Cmd) {
+	newInput := func(placeholder string) textinput.Model {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = placeholder
+		input.Width = max(20, min(60, m.width-16))
+		return input
+	}
+
+	latest, hasLatest := internal.LatestVersion(m.tags)
+	form := tagForm{
+		latest:    latest,
+		hasLatest: hasLatest,
+		name:      newInput("tag name"),
+		commit:    newInput("commit, branch or HEAD~n"),
+		message:   newInput("what this release contains"),
+	}
+	form.commit.SetValue("HEAD")
+	form.name.SetValue(latest.Bump(internal.VersionBumps[0]).String())
+	form = form.resolveCommit()
+
+	m.form = form
+	m.creating = true
+	return m, textinput.Blink
+}
+
+// resolveCommit shows which commit the commit field points at.
+func (f tagForm) resolveCommit() tagForm {
+	hash, err := internal.ResolveCommit(strings.TrimSpace(f.commit.Value()))
+	if err != nil {
+		f.commitInfo, f.commitError = err.Error(), true
+		return f
+	}
+	f.commitInfo, f.commitError = shortHash(hash), false
+	if details, err := internal.GetCommitDetails(hash); err == nil {
+		f.commitInfo += " " + details.Message
+	}
+	return f
+}
+
+func (f tagForm) setFocus(field tagField) tagForm {
+	f.focus = (field + tagFieldCount) % tagFieldCount
+	f.name.Blur()
+	f.commit.Blur()
+	f.message.Blur()
+	switch f.focus {
+	case tagFieldName:
+		f.name.Focus()
+	case tagFieldCommit:
+		f.commit.Focus()
+	case tagFieldMessage:
+		f.message.Focus()
+	}
+	return f
+}
+
+func (m tagModel) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.form
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.creating = false
+		return m, nil
+	case "ctrl+s":
+		name := strings.TrimSpace(f.name.Value())
+		err := internal.CreateTag(internal.TagOptions{
+			Name:    name,
+			Commit:  strings.TrimSpace(f.commit.Value()),
+			Message: f.message.Value(),
+			Sign:    f.sign,
+		})
+		if err != nil {
+			m.form.err = err.Error()
+			return m, nil
+		}
+		m.creating = false
+		m = m.refresh()
+		m.message, m.isError = "Created tag "+name+" (p: push to "+m.remote+")", false
+		for i, tag := range m.tags {
+			if tag.Name == name {
+				m.list.Select(i)
+			}
+		}
+		return m, nil
+	case "tab", "down", "enter":
+		m.form = f.setFocus(f.focus + 1)
+		if f.focus == tagFieldCommit {
+			m.form = m.form.resolveCommit()
+		}
+		return m, nil
+	case "shift+tab", "up":
+		m.form = f.setFocus(f.focus - 1)
+		if f.focus == tagFieldCommit {
+			m.form = m.form.resolveCommit()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	switch f.focus {
+	case tagFieldVersion:
+		switch msg.String() {
+		case "left", "h":
+			f.bump = (f.bump + len(internal.VersionBumps) - 1) % len(internal.VersionBumps)
+		case "right", "l":
+			f.bump = (f.bump + 1) % len(internal.VersionBumps)
+		default:
+			return m, nil
+		}
+		f.name.SetValue(f.latest.Bump(internal.VersionBumps[f.bump]).String())
+	case tagFieldName:
+		f.name, cmd = f.name.Update(msg)
+	case tagFieldCommit:
+		f.commit, cmd = f.commit.Update(msg)
+	case tagFieldMessage:
+		f.message, cmd = f.message.Update(msg)
+	case tagFieldSign:
+		switch msg.String() {
+		case " ", "y", "n":
+			f.sign = msg.String() == "y" || (msg.String() == " " && !f.sign)
+		}
+	}
+	m.form = f
+	return m, cmd
+}
+
+func (m tagModel) renderForm() string {
+	f := m.form
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Width(10)
+	focusedLabelStyle := labelStyle.Foreground(lipgloss.Color("170")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	field := func(t tagField, value string) string {
+		style := labelStyle
+		if f.focus == t {
+			style = focusedLabelStyle
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, style.Render(tagFieldLabels[t]), value)
+	}
+
+	var bumps []string
+	for i, kind := range internal.VersionBumps {
+		text := kind + " " + f.latest.Bump(kind).String()
+		if i == f.bump {
+			bumps = append(bumps, titleStyle.Render("["+text+"]"))
+		} else {
+			bumps = append(bumps, dimStyle.Render(text))
+		}
+	}
+	latest := "no version tags yet"
+	if f.hasLatest {
+		latest = "latest " + f.latest.String()
+	}
+
+	commitInfo := dimStyle.Render(f.commitInfo)
+	if f.commitError {
+		commitInfo = errorStyle.Render(f.commitInfo)
+	}
+
+	sign := "[ ] no"
+	if f.sign {
+		sign = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("[x] yes, with your GPG/SSH signing key")
+	}
+
+	lines := []string{
+		titleStyle.Render("New annotated tag"),
+		"",
+		field(tagFieldVersion, strings.Join(bumps, "  ")+dimStyle.Render("  ("+latest+")")),
+		field(tagFieldName, f.name.View()),
+		field(tagFieldCommit, f.commit.View()),
+		labelStyle.Render("") + commitInfo,
+		field(tagFieldMessage, f.message.View()),
+		field(tagFieldSign, sign),
+	}
+	if f.err != "" {
+		lines = append(lines, "", errorStyle.Render("✗ "+f.err))
+	}
+	lines = append(lines, "", dimStyle.Render("tab/shift+tab: next/previous field • ←/→: choose bump • space: toggle sign • ctrl+s: create • esc: cancel"))
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func (m tagModel) View() string {
+	if m.creating {
+		return m.renderForm()
+	}
+
+	if m.showDetails {
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.detailsHeader(),
+			m.diff.View(),
+			"",
+			m.diffHelp(),
+		)
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	view := m.list.View()
+	if len(m.tags) == 0 {
+		view = lipgloss.NewStyle().Padding(1, 2).Render("No tags yet. Press n to create one.")
+	}
+
+	var status string
+	switch {
+	case m.confirmDelete:
+		names := m.actionNames()
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true).
+			Render(fmt.Sprintf("Delete %s? y: locally • r: locally and on %s • any other key: cancel", strings.Join(names, ", "), m.remote))
+	case m.message != "" && m.isError:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	case m.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	}
+	if status != "" {
+		view += "\n" + status
+	}
+
+	help := "↑↓: navigate • enter: details • space: select • n: new tag • p: push • d: delete • s: sort by date/version • q: quit"
+	view += "\n\n" + helpStyle.Render(help)
+	return view
+}
+
+func init() {
+	tagsCmd.Flags().String("sort", internal.TagSortVersion, "Sort tags by \"version\" or \"date\"")
+	tagsCmd.Flags().StringP("remote", "r", "", "Remote to push tags to and delete them from (default from git config smak.defaultRemote, else origin)")
+	tagsCmd.Flags().BoolP("split", "s", false, "Show diffs side by side (default from git config smak.diffStyle)")
+	rootCmd.AddCommand(tagsCmd)
+}
@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/nikitaNotFound/smak-cli/internal"
+)
+
+var worktreeCmd = &cobra.Command{
+	Use:   "w",
+	Short: "Browse and manage worktrees",
+	Long: `Interactive worktree manager: list worktrees with their branch, state and
+path, add a worktree for a branch, remove, prune, lock and unlock worktrees.
+
+Enter prints the highlighted worktree's path. With --pick the interface is
+drawn on stderr so the path can be captured, e.g. cd "$(smak w --pick)".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkGitRepo(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		worktrees, err := internal.GetWorktrees()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting worktrees: %v\n", err)
+			os.Exit(1)
+		}
+
+		pick, _ := cmd.Flags().GetBool("pick")
+		options := []tea.ProgramOption{tea.WithAltScreen()}
+		if pick {
+			options = append(options, tea.WithOutput(os.Stderr))
+		}
+
+		p := tea.NewProgram(newWorktreeModel(worktrees), options...)
+		final, err := p.Run()
+		if err != nil {
+			log.Fatalf("Error running program: %v", err)
+		}
+
+		chosen := final.(worktreeModel).chosen
+		if chosen == "" {
+			if pick {
+				// Fail so that cd "$(smak w --pick)" does not jump home
+				os.Exit(1)
+			}
+			return
+		}
+		fmt.Println(chosen)
+	},
+}
+
+type worktreeItem struct {
+	worktree internal.Worktree
+}
+
+func (i worktreeItem) Title() string {
+	w := i.worktree
+	title := w.Branch
+	switch {
+	case w.Bare:
+		title = "(bare)"
+	case title == "":
+		title = "(detached at " + shortHash(w.Head) + ")"
+	}
+	if w.Main {
+		title += " [main]"
+	}
+	if w.Current {
+		title += " [current]"
+	}
+	return title
+}
+
+func (i worktreeItem) Description() string {
+	w := i.worktree
+	parts := []string{w.Path}
+	switch {
+	case w.Prunable:
+		parts = append(parts, "missing: "+w.PrunableReason)
+	case w.Dirty:
+		parts = append(parts, "uncommitted changes")
+	case !w.Bare:
+		parts = append(parts, "clean")
+	}
+	if w.Locked {
+		locked := "locked"
+		if w.LockReason != "" {
+			locked += ": " + w.LockReason
+		}
+		parts = append(parts, locked)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func (i worktreeItem) FilterValue() string {
+	return i.worktree.Path
+}
+
+type worktreeModel struct {
+	list      list.Model
+	worktrees []internal.Worktree
+	width     int
+	height    int
+
+	// chosen is the path printed when the program exits
+	chosen string
+
+	choosingBranch bool
+	branchList     list.Model
+	enteringPath   bool
+	addBranch      string
+	pathInput      textinput.Model
+
+	confirmRemove bool
+	message       string
+	isError       bool
+}
+
+func newWorktreeModel(worktrees []internal.Worktree) worktreeModel {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Worktrees"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	m := worktreeModel{list: l}
+	return m.setWorktrees(worktrees)
+}
+
+func (m worktreeModel) setWorktrees(worktrees []internal.Worktree) worktreeModel {
+	items := make([]list.Item, len(worktrees))
+	for i, worktree := range worktrees {
+		items[i] = worktreeItem{worktree: worktree}
+	}
+	m.worktrees = worktrees
+	m.list.SetItems(items)
+	if m.list.Index() >= len(items) {
+		m.list.Select(max(0, len(items)-1))
+	}
+	return m
+}
+
+func (m worktreeModel) current() (internal.Worktree, bool) {
+	if len(m.worktrees) == 0 {
+		return internal.Worktree{}, false
+	}
+	return m.worktrees[m.list.Index()], true
+}
+
+func (m worktreeModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m worktreeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		h, v := lipgloss.NewStyle().GetFrameSize()
+		helpHeight := 4
+		m.list.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		if m.choosingBranch {
+			m.branchList.SetSize(msg.Width-h, msg.Height-v-helpHeight)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.enteringPath {
+			return m.updatePath(msg)
+		}
+
+		if m.choosingBranch {
+			switch msg.String() {
+			case "esc":
+				m.choosingBranch = false
+				return m, nil
+			case "enter":
+				m.choosingBranch = false
+				if item, ok := m.branchList.SelectedItem().(branchItem); ok {
+					return m.startPath(item.branch.Name)
+				}
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.branchList, cmd = m.branchList.Update(msg)
+			return m, cmd
+		}
+
+		if m.confirmRemove {
+			m.confirmRemove = false
+			worktree, ok := m.current()
+			switch msg.String() {
+			case "y":
+				if ok && !worktree.Dirty {
+					return m.run(internal.RemoveWorktree(worktree.Path, false), "Removed "+worktree.Path), nil
+				}
+			case "f":
+				if ok && worktree.Dirty {
+					return m.run(internal.RemoveWorktree(worktree.Path, true), "Removed "+worktree.Path), nil
+				}
+			}
+			return m, nil
+		}
+
+		m.message = ""
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "a":
+			return m.startBranchChoice()
+		case "P":
+			report, err := internal.PruneWorktrees()
+			if report == "" {
+				report = "Nothing to prune"
+			}
+			return m.run(err, report), nil
+		}
+
+		worktree, ok := m.current()
+		if !ok {
+			break
+		}
+		switch msg.String() {
+		case "enter":
+			m.chosen = worktree.Path
+			return m, tea.Quit
+		case "d":
+			switch {
+			case worktree.Main:
+				m.message, m.isError = "The main worktree cannot be removed", true
+			case worktree.Current:
+				m.message, m.isError = "Cannot remove the worktree smak is running in", true
+			case worktree.Locked:
+				m.message, m.isError = worktree.Path+" is locked, unlock it first with l", true
+			default:
+				m.confirmRemove = true
+			}
+			return m, nil
+		case "l":
+			if worktree.Locked {
+				return m.run(internal.UnlockWorktree(worktree.Path), "Unlocked "+worktree.Path), nil
+			}
+			return m.run(internal.LockWorktree(worktree.Path, ""), "Locked "+worktree.Path), nil
+		}
+	}
+
+	if m.enteringPath {
+		var cmd tea.Cmd
+		m.pathInput, cmd = m.pathInput.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// startBranchChoice lists the branches that are not checked out anywhere.
+func (m worktreeModel) startBranchChoice() (tea.Model, tea.Cmd) {
+	branches, err := internal.GetBranches()
+	if err != nil {
+		m.message, m.isError = "Error getting branches: "+err.Error(), true
+		return m, nil
+	}
+	checkedOut := make(map[string]bool)
+	for _, worktree := range m.worktrees {
+		checkedOut[worktree.Branch] = true
+	}
+
+	var items []list.Item
+	for _, branch := range branches {
+		if branch.Worktree == "" && !checkedOut[branch.Name] {
+			items = append(items, branchItem{branch: branch})
+		}
+	}
+	if len(items) == 0 {
+		m.message, m.isError = "Every branch is already checked out in a worktree", true
+		return m, nil
+	}
+
+	m.branchList = list.New(items, list.NewDefaultDelegate(), m.list.Width(), m.list.Height())
+	m.branchList.Title = "Add a worktree for branch"
+	m.branchList.SetShowStatusBar(false)
+	m.branchList.SetFilteringEnabled(false)
+	m.branchList.SetShowHelp(false)
+	m.choosingBranch = true
+	return m, nil
+}
+
+func (m worktreeModel) startPath(branch string) (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Placeholder = "directory for the new worktree"
+	if len(m.worktrees) > 0 {
+		input.SetValue(internal.DefaultWorktreePath(m.worktrees[0].Path, branch))
+	}
+	input.Width = max(20, m.width-10)
+	input.Focus()
+
+	m.addBranch = branch
+	m.pathInput = input
+	m.enteringPath = true
+	return m, textinput.Blink
+}
+
+func (m worktreeModel) updatePath(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.enteringPath = false
+		return m, nil
+	case "enter":
+		path := strings.TrimSpace(m.pathInput.Value())
+		if path == "" {
+			return m, nil
+		}
+		m.enteringPath = false
+		m = m.run(internal.AddWorktree(path, m.addBranch), "Added worktree for "+m.addBranch+" at "+path)
+		for i, worktree := range m.worktrees {
+			if worktree.Branch == m.addBranch {
+				m.list.Select(i)
+			}
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.pathInput, cmd = m.pathInput.Update(msg)
+	return m, cmd
+}
+
+// run reports the outcome of an action and reloads the worktrees.
+func (m worktreeModel) run(err error, success string) worktreeModel {
+	worktrees, listErr := internal.GetWorktrees()
+	if listErr == nil {
+		m = m.setWorktrees(worktrees)
+	}
+
+	switch {
+	case err != nil:
+		m.message, m.isError = err.Error(), true
+	case listErr != nil:
+		m.message, m.isError = "Error reloading worktrees: "+listErr.Error(), true
+	default:
+		m.message, m.isError = success, false
+	}
+	return m
+}
+
+func (m worktreeModel) View() string {
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	if m.choosingBranch {
+		return m.branchList.View() + "\n\n" + helpStyle.Render("↑↓: navigate • enter: choose • esc: cancel")
+	}
+
+	view := m.list.View()
+
+	var status string
+	switch {
+	case m.enteringPath:
+		status = "Worktree directory for " + m.addBranch + ":\n" + m.pathInput.View()
+	case m.confirmRemove:
+		worktree, _ := m.current()
+		text := "Remove the worktree at " + worktree.Path + "? y: remove • any other key: cancel"
+		if worktree.Dirty {
+			text = worktree.Path + " has uncommitted changes that will be lost. f: remove anyway • any other key: cancel"
+		}
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true).Render(text)
+	case m.message != "" && m.isError:
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message)
+	case m.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message)
+	}
+	if status != "" {
+		view += "\n" + status
+	}
+
+	help := "↑↓: navigate • enter: print path • a: add for branch • d: remove • l: lock/unlock • P: prune • q: quit"
+	if m.enteringPath {
+		help = "enter: create • esc: cancel"
+	}
+	return view + "\n\n" + helpStyle.Render(help)
+}
+
+func init() {
+	worktreeCmd.Flags().Bool("pick", false, "Draw on stderr and print only the chosen worktree's path, for cd \"$(smak w --pick)\"")
+	rootCmd.AddCommand(worktreeCmd)
+}
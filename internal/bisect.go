@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"math/bits"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Marks for the commit a bisect has checked out.
+const (
+	BisectGood = "good"
+	BisectBad  = "bad"
+	BisectSkip = "skip"
+)
+
+// BisectState is the progress of a bisect. Remaining counts the commits
+// that still have to be tested and Steps estimates how many more marks that
+// takes. When Remaining reaches zero FirstBad is the first bad commit, or
+// Suspects lists the commits it could be when skipped commits are in the way.
+type BisectState struct {
+	Active    bool
+	Bad       string
+	Good      []string
+	Skipped   []string
+	Current   string
+	Remaining int
+	Steps     int
+	FirstBad  string
+	Suspects  []string
+}
+
+// Done reports whether the bisect has narrowed the range down.
+func (s *BisectState) Done() bool {
+	return s.Active && s.Bad != "" && len(s.Good) > 0 && s.Remaining == 0
+}
+
+// GetBisectState reads the bisect in progress from the refs/bisect refs.
+func GetBisectState() (*BisectState, error) {
+	state := &BisectState{}
+	start, err := gitPath("BISECT_START")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(start); err != nil {
+		return state, nil
+	}
+	state.Active = true
+	state.Current, _ = revParse("HEAD")
+	state.Bad, _ = revParse("refs/bisect/bad")
+
+	output, err := gitCommand("for-each-ref", "--format=%(refname)|%(objectname)", "refs/bisect/").Output()
+	if err != nil {
+		return nil, err
+	}
+	skipped := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		ref, hash, ok := strings.Cut(line, "|")
+		switch {
+		case !ok:
+		case strings.HasPrefix(ref, "refs/bisect/good-"):
+			state.Good = append(state.Good, hash)
+		case strings.HasPrefix(ref, "refs/bisect/skip-"):
+			state.Skipped = append(state.Skipped, hash)
+			skipped[hash] = true
+		}
+	}
+	if state.Bad == "" || len(state.Good) == 0 {
+		return state, nil
+	}
+
+	// The candidates are the commits that can be the first bad one: the bad
+	// commit and its ancestors that no good commit contains
+	args := append([]string{"rev-list", state.Bad, "--not"}, state.Good...)
+	output, err = gitCommand(args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	var suspects []string
+	for _, hash := range strings.Fields(string(output)) {
+		if hash == state.Bad {
+			continue
+		}
+		if skipped[hash] {
+			suspects = append(suspects, hash)
+		} else {
+			state.Remaining++
+		}
+	}
+	state.Steps = bits.Len(uint(state.Remaining))
+
+	if state.Remaining == 0 {
+		if len(suspects) == 0 {
+			state.FirstBad = state.Bad
+		} else {
+			state.Suspects = append([]string{state.Bad}, suspects...)
+		}
+	}
+	return state, nil
+}
+
+// StartBisect starts bisecting between a bad and a good commit and checks
+// out the first commit to test.
+func StartBisect(bad, good string) error {
+	return runWithOutput(gitCommand("bisect", "start", bad, good, "--"))
+}
+
+// MarkBisect marks the checked out commit as good, bad or skipped, which
+// checks out the next commit to test.
+func MarkBisect(mark string) error {
+	return runWithOutput(gitCommand("bisect", mark))
+}
+
+// BisectRunCommand builds a bisect run of a script: exit code 0 marks the
+// commit good, 125 skips it and any other code up to 127 marks it bad. The
+// run can be interrupted with StopCommand.
+func BisectRunCommand(script []string) *exec.Cmd {
+	cmd := gitCommand(append([]string{"bisect", "run"}, script...)...)
+	ownProcessGroup(cmd)
+	return cmd
+}
+
+// ResetBisect ends the bisect and checks out the branch it started on.
+func ResetBisect() error {
+	return runWithOutput(gitCommand("bisect", "reset"))
+}
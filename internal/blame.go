@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileCommit is a commit in a file's history together with the path the
+// file had in that commit, which differs from today's path across renames.
+type FileCommit struct {
+	Commit
+	Path string
+}
+
+// GetFileHistory lists the commits that touched path, newest first,
+// following the file through renames.
+func GetFileHistory(path string) ([]FileCommit, error) {
+	cmd := gitCommand("log", "--follow", "--name-only", "--pretty=format:%x1e"+commitLogFormat, "--date=iso", "--", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var history []FileCommit
+	currentPath := path
+	for _, record := range strings.Split(string(output), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		commit, ok := parseCommitLine(lines[0])
+		if !ok {
+			continue
+		}
+		// Merges list no files; they keep the path of the newer commit
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				currentPath = line
+			}
+		}
+		history = append(history, FileCommit{Commit: commit, Path: currentPath})
+	}
+
+	return history, nil
+}
+
+// BlameLine is one line of `git blame` output. Previous and PreviousPath
+// name the parent commit and the path the line's file had there, which is
+// where to continue blaming to see what the line looked like before.
+type BlameLine struct {
+	Hash         string
+	Line         int
+	Content      string
+	Author       string
+	Date         time.Time
+	Summary      string
+	Path         string
+	Previous     string
+	PreviousPath string
+}
+
+// Uncommitted reports whether the line was changed in the working tree.
+func (l BlameLine) Uncommitted() bool {
+	return strings.Trim(l.Hash, "0") == ""
+}
+
+// Blame annotates every line of path with the commit that last changed it.
+// An empty rev blames the working tree version of the file.
+func Blame(rev, path string) ([]BlameLine, error) {
+	args := []string{"blame", "--porcelain"}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	output, err := gitCommand(append(args, "--", path)...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(string(output)), nil
+}
+
+// parseBlame reads `git blame --porcelain` output. The format only describes
+// a commit the first time it appears.
+func parseBlame(output string) []BlameLine {
+	commits := make(map[string]*BlameLine)
+	var lines []BlameLine
+	var current *BlameLine
+	for _, line := range strings.Split(output, "\n") {
+		if content, ok := strings.CutPrefix(line, "\t"); ok {
+			if current != nil {
+				blamed := *current
+				blamed.Content = content
+				lines = append(lines, blamed)
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && len(fields[0]) == 40 {
+			info, ok := commits[fields[0]]
+			if !ok {
+				info = &BlameLine{Hash: fields[0]}
+				commits[fields[0]] = info
+			}
+			info.Line, _ = strconv.Atoi(fields[2])
+			current = info
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-time":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.Date = time.Unix(seconds, 0)
+			}
+		case "summary":
+			current.Summary = value
+		case "filename":
+			current.Path = value
+		case "previous":
+			current.Previous, current.PreviousPath, _ = strings.Cut(value, " ")
+		}
+	}
+
+	return lines
+}
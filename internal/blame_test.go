@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	blameFirst  = "fcd3d7febd1b5e9a0f9edff6914d1f49bb3bfe0d"
+	blameSecond = "6212b2ae2b65edeba18aabe8e8938b81bfb18b19"
+)
+
+// blamePorcelain is `git blame --porcelain` of a file whose second line was
+// changed in a later commit. The first commit is only described once.
+var blamePorcelain = strings.Join([]string{
+	blameFirst + " 1 1 1",
+	"author Ann",
+	"author-mail <ann@example.com>",
+	"author-time 1700000000",
+	"author-tz +0000",
+	"committer Ann",
+	"committer-mail <ann@example.com>",
+	"committer-time 1700000000",
+	"committer-tz +0000",
+	"summary add a",
+	"boundary",
+	"filename a.txt",
+	"\tone",
+	blameSecond + " 2 2 1",
+	"author Bob",
+	"author-mail <bob@example.com>",
+	"author-time 1700100000",
+	"author-tz +0000",
+	"committer Ann",
+	"committer-mail <ann@example.com>",
+	"committer-time 1700100000",
+	"committer-tz +0000",
+	"summary change two",
+	"previous " + blameFirst + " a.txt",
+	"filename a.txt",
+	"\tTWO",
+	blameFirst + " 3 3 1",
+	"\tthree",
+	"",
+}, "\n")
+
+func TestParseBlame(t *testing.T) {
+	lines := parseBlame(blamePorcelain)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+
+	want := []BlameLine{
+		{Hash: blameFirst, Line: 1, Content: "one", Author: "Ann", Date: time.Unix(1700000000, 0), Summary: "add a", Path: "a.txt"},
+		{Hash: blameSecond, Line: 2, Content: "TWO", Author: "Bob", Date: time.Unix(1700100000, 0), Summary: "change two", Path: "a.txt",
+			Previous: blameFirst, PreviousPath: "a.txt"},
+		{Hash: blameFirst, Line: 3, Content: "three", Author: "Ann", Date: time.Unix(1700000000, 0), Summary: "add a", Path: "a.txt"},
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i+1, lines[i], want[i])
+		}
+	}
+}
+
+func TestBlameLineUncommitted(t *testing.T) {
+	if !(BlameLine{Hash: strings.Repeat("0", 40)}).Uncommitted() {
+		t.Error("the all-zero hash is not reported as uncommitted")
+	}
+	if (BlameLine{Hash: blameFirst}).Uncommitted() {
+		t.Error("a commit hash is reported as uncommitted")
+	}
+}
@@ -0,0 +1,349 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DryRun stops git commands that would change the repository or a remote
+// from running. Each one is recorded and replaced by a call to the hidden
+// DryRunCommand, which only prints what would have happened, so the text
+// still shows up wherever the command's output is displayed.
+var DryRun bool
+
+// DryRunCommand is the hidden smak subcommand that dry-run commands are
+// redirected to.
+const DryRunCommand = "__dry-run"
+
+var (
+	dryRunMu  sync.Mutex
+	dryRunLog []string
+)
+
+// gitCommand builds every git invocation smak makes, which is what lets the
+// dry-run mode intercept mutating commands in one place.
+func gitCommand(args ...string) *exec.Cmd {
+	if !DryRun || !mutates(args) {
+		return exec.Command("git", args...)
+	}
+
+	dryRunMu.Lock()
+	dryRunLog = append(dryRunLog, DescribeGitCommand(args))
+	dryRunMu.Unlock()
+
+	self, err := os.Executable()
+	if err != nil {
+		self = os.Args[0]
+	}
+	return exec.Command(self, append([]string{DryRunCommand}, args...)...)
+}
+
+// DryRunLog returns the descriptions of the commands skipped so far.
+func DryRunLog() []string {
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	return slices.Clone(dryRunLog)
+}
+
+// DescribeGitCommand renders a git command line together with its expected
+// effect.
+func DescribeGitCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"$\\*?;&|<>()") {
+			quoted[i] = shellQuote(arg)
+		} else {
+			quoted[i] = arg
+		}
+	}
+	return fmt.Sprintf("[dry-run] git %s\n          would %s", strings.Join(quoted, " "), gitEffect(args))
+}
+
+// valueFlags lists the flags of a subcommand whose value is the next
+// argument, such as the message of `tag -m`. (`branch -m` takes no value.)
+var valueFlags = map[string][]string{
+	"commit":     {"-m", "--message", "-F", "--file"},
+	"merge":      {"-m", "-F", "--file"},
+	"tag":        {"-m", "--message", "-F", "--file"},
+	"stash":      {"-m", "--message"},
+	"update-ref": {"-m"},
+}
+
+// splitGitArgs separates the subcommand from the global "-c key=value" and
+// "-C dir" options. The values of its valueFlags are left out of the
+// arguments so they are not taken for positional ones.
+func splitGitArgs(args []string) (string, []string) {
+	for len(args) >= 2 && (args[0] == "-c" || args[0] == "-C") {
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+
+	sub, rest := args[0], args[1:]
+	flags := valueFlags[sub]
+	if len(flags) == 0 {
+		return sub, rest
+	}
+	var kept []string
+	for i := 0; i < len(rest); i++ {
+		kept = append(kept, rest[i])
+		if rest[i] == "--" {
+			return sub, append(kept, rest[i+1:]...)
+		}
+		if slices.Contains(flags, rest[i]) {
+			i++
+		}
+	}
+	return sub, kept
+}
+
+// positional returns the arguments that are not flags.
+func positional(args []string) []string {
+	var result []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(result, args[i+1:]...)
+		}
+		if !strings.HasPrefix(arg, "-") {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+func hasAny(args []string, flags ...string) bool {
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(flags, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mutates reports whether a git command can change the repository, its
+// working tree or a remote. Unknown commands are assumed to mutate.
+func mutates(args []string) bool {
+	sub, rest := splitGitArgs(args)
+	switch sub {
+	case "log", "show", "diff", "status", "rev-parse", "rev-list", "for-each-ref", "ls-files",
+		"ls-remote", "cat-file", "merge-base", "blame", "describe", "shortlog", "name-rev",
+		"var", "version", "check-ignore", "check-ref-format", "grep", "help":
+		return false
+	case "symbolic-ref":
+		return len(positional(rest)) > 1
+	case "config":
+		return !hasAny(rest, "--get", "--get-all", "--get-regexp", "--list", "-l")
+	case "branch":
+		if hasAny(rest, "-d", "-D", "--delete", "-m", "-M", "--move", "-c", "-C", "--copy",
+			"-f", "--force", "-u", "--set-upstream-to", "--unset-upstream", "--edit-description") {
+			return true
+		}
+		if hasAny(rest, "--list", "-l", "-r", "-a", "--all", "--contains", "--merged", "--no-merged", "--show-current") {
+			return false
+		}
+		return len(positional(rest)) > 0
+	case "tag":
+		if hasAny(rest, "-d", "--delete", "-a", "--annotate", "-s", "--sign", "-f", "--force", "-m", "--message") {
+			return true
+		}
+		return !hasAny(rest, "-l", "--list", "--contains", "--points-at", "-n") && len(positional(rest)) > 0
+	case "stash", "worktree", "reflog", "remote", "bisect":
+		if len(rest) == 0 {
+			return sub == "stash"
+		}
+		switch rest[0] {
+		case "list", "show", "get-url", "log", "visualize", "view", "-v", "--verbose":
+			return false
+		}
+		return true
+	case "apply":
+		return !hasAny(rest, "--check", "--stat", "--numstat", "--summary")
+	}
+	return true
+}
+
+// gitEffect describes in a few words what a mutating command would do.
+func gitEffect(args []string) string {
+	sub, rest := splitGitArgs(args)
+	targets := strings.Join(positional(rest), " ")
+
+	for _, flag := range []string{"--continue", "--abort", "--skip", "--quit"} {
+		if hasAny(rest, flag) {
+			return strings.TrimPrefix(flag, "--") + " the " + sub + " in progress"
+		}
+	}
+
+	switch sub {
+	case "add":
+		if targets == "" {
+			return "stage all changes"
+		}
+		return "stage " + targets
+	case "apply":
+		switch {
+		case hasAny(rest, "--cached") && hasAny(rest, "--reverse", "-R"):
+			return "unstage the selected hunks"
+		case hasAny(rest, "--cached"):
+			return "stage the selected hunks"
+		case hasAny(rest, "--reverse", "-R"):
+			return "discard the selected hunks from the working tree"
+		}
+		return "apply a patch to the working tree"
+	case "restore":
+		if hasAny(rest, "--staged", "-S") {
+			return "unstage " + targets
+		}
+		return "discard the working tree changes to " + targets
+	case "clean":
+		return "delete untracked " + targets
+	case "rm":
+		if hasAny(rest, "--cached") {
+			return "remove " + targets + " from the index"
+		}
+		return "delete " + targets
+	case "branch":
+		switch {
+		case hasAny(rest, "-d", "--delete"):
+			return "delete branch " + targets + " if it is merged"
+		case hasAny(rest, "-D"):
+			return "delete branch " + targets + " even if it is not merged"
+		case hasAny(rest, "-m", "-M", "--move"):
+			return "rename branch " + targets
+		}
+		return "create or update branch " + targets
+	case "checkout", "switch":
+		return "switch the working tree to " + targets
+	case "commit":
+		if hasAny(rest, "--amend") {
+			return "replace the latest commit with an amended one"
+		}
+		return "create a new commit from the staged changes"
+	case "merge":
+		return "merge " + targets + " into the current branch"
+	case "cherry-pick":
+		return "apply " + targets + " on top of the current branch"
+	case "revert":
+		return "create commits reverting " + targets
+	case "rebase":
+		return "rewrite the commits of the current branch"
+	case "push":
+		if args := positional(rest); hasAny(rest, "-d", "--delete") && len(args) > 1 {
+			return "delete " + strings.Join(args[1:], " ") + " on " + args[0]
+		}
+		if hasAny(rest, "--force-with-lease") {
+			return "force-push to " + targets + " unless the remote branch moved since the last fetch"
+		}
+		if hasAny(rest, "-f", "--force") {
+			return "force-push to " + targets + ", overwriting the remote branch"
+		}
+		return "push to " + targets
+	case "fetch":
+		return "download objects and update remote-tracking branches"
+	case "update-ref":
+		if hasAny(rest, "-d") {
+			return "delete " + targets
+		}
+		if args := positional(rest); len(args) > 1 {
+			return "move " + args[0] + " to " + args[1]
+		}
+		return "update " + targets
+	case "pull":
+		return "fetch and integrate remote changes into the current branch"
+	case "reset":
+		switch {
+		case hasAny(rest, "--hard"):
+			return "move the current branch to " + targets + " and discard all uncommitted changes"
+		case hasAny(rest, "--soft"):
+			return "move the current branch to " + targets + ", keeping the changes staged"
+		}
+		return "move the current branch to " + targets + ", keeping the changes unstaged"
+	case "stash":
+		if len(rest) == 0 {
+			return "stash all changes"
+		}
+		switch rest[0] {
+		case "push":
+			return "stash all changes"
+		case "apply":
+			return "apply " + strings.Join(positional(rest[1:]), " ") + " to the working tree"
+		case "pop":
+			return "apply " + strings.Join(positional(rest[1:]), " ") + " to the working tree and drop it"
+		case "drop":
+			return "delete " + strings.Join(positional(rest[1:]), " ")
+		case "store":
+			return "save " + strings.Join(positional(rest[1:]), " ") + " as a stash"
+		case "branch":
+			return "create branch " + strings.Join(positional(rest[1:]), " ") + " from the stash and pop it"
+		}
+		return "change stash state: " + targets
+	case "tag":
+		if hasAny(rest, "-d", "--delete") {
+			return "delete tag " + targets
+		}
+		kind := "tag"
+		if hasAny(rest, "-s", "--sign") {
+			kind = "signed tag"
+		}
+		if args := positional(rest); len(args) > 1 {
+			return "create " + kind + " " + args[0] + " at " + args[1]
+		}
+		return "create " + kind + " " + targets
+	case "worktree":
+		if len(rest) == 0 {
+			break
+		}
+		paths := strings.Join(positional(rest[1:]), " ")
+		switch rest[0] {
+		case "add":
+			return "create a worktree: " + paths
+		case "remove":
+			return "delete the worktree at " + paths
+		case "prune":
+			return "forget worktrees whose directory is missing"
+		case "lock":
+			return "lock the worktree at " + paths
+		case "unlock":
+			return "unlock the worktree at " + paths
+		}
+		return "change worktree state: " + targets
+	case "remote":
+		if args := positional(rest); len(args) > 1 {
+			switch args[0] {
+			case "add":
+				return "add the remote " + strings.Join(args[1:], " at ")
+			case "remove", "rm":
+				return "remove the remote " + args[1] + " and its remote-tracking branches"
+			case "rename":
+				return "rename the remote " + strings.Join(args[1:], " to ")
+			}
+		}
+		return "change remote state: " + targets
+	case "bisect":
+		if len(rest) == 0 {
+			break
+		}
+		switch rest[0] {
+		case "start":
+			if args := positional(rest[1:]); len(args) > 1 {
+				return "start bisecting between bad " + args[0] + " and good " + strings.Join(args[1:], " ") + ", checking out a commit to test"
+			}
+			return "start bisecting"
+		case "good", "bad", "skip":
+			return "mark the checked out commit as " + rest[0] + " and check out the next one to test"
+		case "run":
+			return "run " + strings.Join(rest[1:], " ") + " on each commit to test until the first bad commit is found"
+		case "reset":
+			return "end the bisect and check out the original branch"
+		}
+		return "change bisect state: " + targets
+	case "reflog", "config":
+		return "change " + sub + " state: " + targets
+	}
+	return "modify the repository"
+}
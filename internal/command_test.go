@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMutates(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"log", "--oneline"}, false},
+		{[]string{"status", "--porcelain=v1", "-z"}, false},
+		{[]string{"rev-parse", "--git-path", "BISECT_START"}, false},
+		{[]string{"symbolic-ref", "--quiet", "--short", "HEAD"}, false},
+		{[]string{"symbolic-ref", "HEAD", "refs/heads/main"}, true},
+
+		{[]string{"tag"}, false},
+		{[]string{"tag", "-l", "v*"}, false},
+		{[]string{"tag", "--list"}, false},
+		{[]string{"tag", "--points-at", "HEAD"}, false},
+		{[]string{"tag", "v1.0.0"}, true},
+		{[]string{"tag", "--message=release", "v1.0.0"}, true},
+		{[]string{"tag", "-a", "-m", "release", "v1.0.0", "HEAD"}, true},
+		{[]string{"tag", "-d", "v1.0.0"}, true},
+
+		{[]string{"branch", "--show-current"}, false},
+		{[]string{"branch", "--list", "feat*"}, false},
+		{[]string{"branch", "feature"}, true},
+		{[]string{"branch", "-D", "feature"}, true},
+		{[]string{"branch", "--set-upstream-to=origin/main"}, true},
+
+		{[]string{"config", "--get", "smak.diffStyle"}, false},
+		{[]string{"config", "--get-regexp", `^remote\.`}, false},
+		{[]string{"config", "smak.defaultRemote", "upstream"}, true},
+		{[]string{"config", "--unset", "smak.defaultRemote"}, true},
+
+		{[]string{"worktree", "list", "--porcelain"}, false},
+		{[]string{"worktree", "add", "../wt", "feature"}, true},
+		{[]string{"worktree", "prune"}, true},
+
+		{[]string{"remote"}, false},
+		{[]string{"remote", "-v"}, false},
+		{[]string{"remote", "get-url", "origin"}, false},
+		{[]string{"remote", "add", "upstream", "../up.git"}, true},
+		{[]string{"remote", "rename", "origin", "upstream"}, true},
+
+		{[]string{"stash"}, true},
+		{[]string{"stash", "list"}, false},
+		{[]string{"stash", "show", "-p", "stash@{0}"}, false},
+		{[]string{"stash", "store", "-m", "message", "abc123"}, true},
+
+		{[]string{"reflog", "show", "main"}, false},
+		{[]string{"reflog", "expire", "--all"}, true},
+
+		{[]string{"bisect", "log"}, false},
+		{[]string{"bisect", "visualize"}, false},
+		{[]string{"bisect", "good"}, true},
+		{[]string{"bisect", "run", "make", "test"}, true},
+		{[]string{"bisect", "reset"}, true},
+
+		{[]string{"apply", "--check", "patch"}, false},
+		{[]string{"apply", "--cached", "patch"}, true},
+
+		{[]string{"-C", "../wt", "log"}, false},
+		{[]string{"-C", "../wt", "pull", "--ff-only"}, true},
+		{[]string{"-c", "core.editor=true", "rebase", "--continue"}, true},
+		{[]string{"-c", "color.ui=never", "diff"}, false},
+
+		{[]string{"update-ref", "refs/heads/main", "abc", "def"}, true},
+		{[]string{"fetch", "--all", "--prune"}, true},
+		{[]string{"push", "origin", "main"}, true},
+		{[]string{"frobnicate"}, true},
+	}
+	for _, tt := range tests {
+		if got := mutates(tt.args); got != tt.want {
+			t.Errorf("mutates(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestGitEffect(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"update-ref", "-m", "smak sync", "refs/heads/feature", "new", "old"}, "move refs/heads/feature to new"},
+		{[]string{"push", "--delete", "origin", "feature"}, "delete feature on origin"},
+		{[]string{"push", "--force-with-lease=refs/heads/main:abc", "origin", "HEAD:refs/heads/main"}, "unless the remote branch moved"},
+		{[]string{"stash", "store", "-m", "renamed", "abc123"}, "save abc123 as a stash"},
+		{[]string{"-C", "../wt", "merge", "--ff-only", "origin/main"}, "merge origin/main into the current branch"},
+		{[]string{"cherry-pick", "--abort"}, "abort the cherry-pick in progress"},
+		{[]string{"remote", "rename", "origin", "upstream"}, "rename the remote origin to upstream"},
+		{[]string{"tag", "-s", "-m", "release", "v1.0.0", "HEAD"}, "create signed tag v1.0.0 at HEAD"},
+		{[]string{"reset", "--hard", "HEAD@{2}"}, "discard all uncommitted changes"},
+		{[]string{"branch", "-m", "old", "new"}, "rename branch old new"},
+		{[]string{"commit", "-m", "subject", "--amend"}, "replace the latest commit"},
+	}
+	for _, tt := range tests {
+		if got := gitEffect(tt.args); !strings.Contains(got, tt.want) {
+			t.Errorf("gitEffect(%q) = %q, want it to contain %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package internal
+
+import "strings"
+
+// Comparison is what differs between two branches or commits: the commits
+// each side has that the other lacks, and where they forked.
+type Comparison struct {
+	Left      string
+	Right     string
+	MergeBase string
+	LeftOnly  []Commit
+	RightOnly []Commit
+}
+
+// CompareRefs lists the commits unique to each side, newest first.
+func CompareRefs(left, right string) (*Comparison, error) {
+	comparison := &Comparison{Left: left, Right: right}
+
+	var err error
+	if comparison.LeftOnly, err = getCommits(right + ".." + left); err != nil {
+		return nil, err
+	}
+	if comparison.RightOnly, err = getCommits(left + ".." + right); err != nil {
+		return nil, err
+	}
+
+	// Unrelated histories have no merge base
+	if output, err := gitCommand("merge-base", left, right).Output(); err == nil {
+		comparison.MergeBase = strings.TrimSpace(string(output))
+	}
+	return comparison, nil
+}
+
+// GetCompareDiff returns the patch between two refs. The three-dot form shows
+// only what right changed since it forked from left, as a merge would bring
+// in; the two-dot form shows every difference between the two trees.
+func GetCompareDiff(left, right string, threeDot bool) (string, error) {
+	rangeArgs := []string{left, right}
+	if threeDot {
+		rangeArgs = []string{left + "..." + right}
+	}
+	args := append([]string{"diff", "--no-color", "-M"}, rangeArgs...)
+	output, err := gitCommand(append(args, "--")...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ConventionalTypes are the commit types accepted by the composer.
+var ConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+const (
+	// MaxHeaderLength is the longest allowed "type(scope)!: subject" line
+	MaxHeaderLength = 72
+	// MaxBodyLineLength is the longest allowed line in the body
+	MaxBodyLineLength = 100
+)
+
+var (
+	conventionalHeaderRe = regexp.MustCompile(`^([a-z]+)(?:\(([^()]+)\))?(!)?: (.+)$`)
+	scopeRe              = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*$`)
+	issueRe              = regexp.MustCompile(`^(#?[0-9]+|[A-Z][A-Z0-9]+-[0-9]+)$`)
+)
+
+// ConventionalCommit is a commit message following the Conventional Commits
+// specification. Closes and Refs hold issue references ("#12", "PROJ-4").
+type ConventionalCommit struct {
+	Type           string
+	Scope          string
+	Breaking       bool
+	BreakingChange string
+	Subject        string
+	Body           string
+	Closes         []string
+	Refs           []string
+}
+
+// Header renders the first line of the message.
+func (c ConventionalCommit) Header() string {
+	header := c.Type
+	if c.Scope != "" {
+		header += "(" + c.Scope + ")"
+	}
+	if c.Breaking {
+		header += "!"
+	}
+	return header + ": " + c.Subject
+}
+
+// Message renders the full commit message with body and trailers.
+func (c ConventionalCommit) Message() string {
+	parts := []string{c.Header()}
+	if body := strings.TrimSpace(c.Body); body != "" {
+		parts = append(parts, body)
+	}
+
+	var trailers []string
+	if c.Breaking && strings.TrimSpace(c.BreakingChange) != "" {
+		trailers = append(trailers, "BREAKING CHANGE: "+strings.TrimSpace(c.BreakingChange))
+	}
+	for _, issue := range c.Closes {
+		trailers = append(trailers, "Closes: "+normalizeIssue(issue))
+	}
+	for _, issue := range c.Refs {
+		trailers = append(trailers, "Refs: "+normalizeIssue(issue))
+	}
+	if len(trailers) > 0 {
+		parts = append(parts, strings.Join(trailers, "\n"))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// Validate returns everything that keeps the message from being a valid
+// conventional commit; an empty result means it can be committed.
+func (c ConventionalCommit) Validate() []string {
+	var problems []string
+
+	if !slices.Contains(ConventionalTypes, c.Type) {
+		problems = append(problems, fmt.Sprintf("type must be one of %s", strings.Join(ConventionalTypes, ", ")))
+	}
+	if c.Scope != "" && !scopeRe.MatchString(c.Scope) {
+		problems = append(problems, "scope may only contain lowercase letters, digits and . _ / -")
+	}
+
+	subject := strings.TrimSpace(c.Subject)
+	switch {
+	case subject == "":
+		problems = append(problems, "subject is required")
+	case strings.HasSuffix(subject, "."):
+		problems = append(problems, "subject must not end with a period")
+	case subject != c.Subject:
+		problems = append(problems, "subject must not start or end with spaces")
+	}
+	if length := len([]rune(c.Header())); length > MaxHeaderLength {
+		problems = append(problems, fmt.Sprintf("header is %d characters, the limit is %d", length, MaxHeaderLength))
+	}
+
+	for i, line := range strings.Split(c.Body, "\n") {
+		if len([]rune(line)) > MaxBodyLineLength {
+			problems = append(problems, fmt.Sprintf("body line %d is longer than %d characters", i+1, MaxBodyLineLength))
+		}
+	}
+
+	for _, issue := range append(slices.Clone(c.Closes), c.Refs...) {
+		if !issueRe.MatchString(issue) {
+			problems = append(problems, fmt.Sprintf("%q is not an issue reference like #123 or PROJ-45", issue))
+		}
+	}
+
+	return problems
+}
+
+// ParseIssues splits a comma or space separated list of issue references.
+func ParseIssues(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+func normalizeIssue(issue string) string {
+	if issue != "" && issue[0] >= '0' && issue[0] <= '9' {
+		return "#" + issue
+	}
+	return issue
+}
+
+// ParseConventionalHeader reads "type(scope)!: subject". ok is false when
+// the line does not follow the convention.
+func ParseConventionalHeader(header string) (ConventionalCommit, bool) {
+	match := conventionalHeaderRe.FindStringSubmatch(header)
+	if match == nil {
+		return ConventionalCommit{}, false
+	}
+	return ConventionalCommit{
+		Type:     match[1],
+		Scope:    match[2],
+		Breaking: match[3] == "!",
+		Subject:  match[4],
+	}, true
+}
+
+// SuggestScopes collects the scopes used in earlier commit subjects, most
+// frequently used first.
+func SuggestScopes(commits []Commit) []string {
+	counts := make(map[string]int)
+	for _, commit := range commits {
+		if parsed, ok := ParseConventionalHeader(commit.Message); ok && parsed.Scope != "" {
+			counts[parsed.Scope]++
+		}
+	}
+
+	scopes := make([]string, 0, len(counts))
+	for scope := range counts {
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		if counts[scopes[i]] != counts[scopes[j]] {
+			return counts[scopes[i]] > counts[scopes[j]]
+		}
+		return scopes[i] < scopes[j]
+	})
+	return scopes
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConventionalCommitValidate(t *testing.T) {
+	valid := ConventionalCommit{Type: "feat", Scope: "ui", Subject: "add a graph", Closes: []string{"12", "#13"}, Refs: []string{"PROJ-4"}}
+
+	tests := []struct {
+		name   string
+		modify func(*ConventionalCommit)
+		want   string
+	}{
+		{"valid", func(*ConventionalCommit) {}, ""},
+		{"scope with path", func(c *ConventionalCommit) { c.Scope = "internal/git.go" }, ""},
+		{"unknown type", func(c *ConventionalCommit) { c.Type = "feature" }, "type must be one of"},
+		{"uppercase scope", func(c *ConventionalCommit) { c.Scope = "UI" }, "scope may only contain"},
+		{"missing subject", func(c *ConventionalCommit) { c.Subject = "  " }, "subject is required"},
+		{"trailing period", func(c *ConventionalCommit) { c.Subject = "add a graph." }, "must not end with a period"},
+		{"surrounding spaces", func(c *ConventionalCommit) { c.Subject = " add a graph" }, "must not start or end with spaces"},
+		{"long header", func(c *ConventionalCommit) { c.Subject = strings.Repeat("x", MaxHeaderLength) }, "header is"},
+		{"long body line", func(c *ConventionalCommit) { c.Body = "fine\n" + strings.Repeat("x", MaxBodyLineLength+1) }, "body line 2"},
+		{"bad issue", func(c *ConventionalCommit) { c.Refs = []string{"proj-4"} }, `"proj-4" is not an issue reference`},
+	}
+	for _, tt := range tests {
+		commit := valid
+		tt.modify(&commit)
+		problems := commit.Validate()
+		switch {
+		case tt.want == "" && len(problems) > 0:
+			t.Errorf("%s: unexpected problems %q", tt.name, problems)
+		case tt.want != "" && (len(problems) != 1 || !strings.Contains(problems[0], tt.want)):
+			t.Errorf("%s: problems = %q, want one containing %q", tt.name, problems, tt.want)
+		}
+	}
+}
+
+func TestConventionalCommitMessage(t *testing.T) {
+	commit := ConventionalCommit{
+		Type:           "feat",
+		Scope:          "api",
+		Breaking:       true,
+		BreakingChange: "the old endpoint is gone",
+		Subject:        "drop v1",
+		Body:           "v2 has been the default for a year.\n",
+		Closes:         []string{"12"},
+		Refs:           []string{"PROJ-4"},
+	}
+	want := "feat(api)!: drop v1\n\n" +
+		"v2 has been the default for a year.\n\n" +
+		"BREAKING CHANGE: the old endpoint is gone\nCloses: #12\nRefs: PROJ-4"
+	if got := commit.Message(); got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+
+	parsed, ok := ParseConventionalHeader(commit.Header())
+	if !ok || parsed.Type != "feat" || parsed.Scope != "api" || !parsed.Breaking || parsed.Subject != "drop v1" {
+		t.Errorf("ParseConventionalHeader(%q) = %+v, %v", commit.Header(), parsed, ok)
+	}
+}
@@ -0,0 +1,346 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+)
+
+type FileChange struct {
+	Path      string
+	OldPath   string
+	Status    string
+	Additions int
+	Deletions int
+	Binary    bool
+}
+
+// FileDiff is the part of a patch that belongs to a single file, starting
+// with its "diff --git" line.
+type FileDiff struct {
+	Path  string
+	Lines []string
+}
+
+// GetCommitFiles returns the files changed by a commit (against its first
+// parent for merges) together with their line statistics.
+func GetCommitFiles(hash string) ([]FileChange, error) {
+	cmd := gitCommand("show", "--format=", "--diff-merges=first-parent", "-M", "--name-status", "-z", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []FileChange
+	fields := strings.Split(strings.TrimRight(string(output), "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		status := strings.TrimSpace(fields[i])
+		if status == "" {
+			continue
+		}
+
+		change := FileChange{Status: status[:1]}
+		if change.Status == "R" || change.Status == "C" {
+			if i+2 >= len(fields) {
+				break
+			}
+			change.OldPath = fields[i+1]
+			change.Path = fields[i+2]
+			i += 2
+		} else {
+			if i+1 >= len(fields) {
+				break
+			}
+			change.Path = fields[i+1]
+			i++
+		}
+		files = append(files, change)
+	}
+
+	stats, err := getNumstat(hash)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if stat, ok := stats[files[i].Path]; ok {
+			files[i].Additions = stat.Additions
+			files[i].Deletions = stat.Deletions
+			files[i].Binary = stat.Binary
+		}
+	}
+
+	return files, nil
+}
+
+func getNumstat(hash string) (map[string]FileChange, error) {
+	cmd := gitCommand("show", "--format=", "--diff-merges=first-parent", "-M", "--numstat", "-z", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]FileChange)
+	fields := strings.Split(strings.TrimRight(string(output), "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(strings.TrimLeft(fields[i], "\n"), "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		stat := FileChange{}
+		if parts[0] == "-" && parts[1] == "-" {
+			stat.Binary = true
+		} else {
+			stat.Additions, _ = strconv.Atoi(parts[0])
+			stat.Deletions, _ = strconv.Atoi(parts[1])
+		}
+
+		path := parts[2]
+		if path == "" {
+			// Renames are written as an empty path followed by old and new names
+			if i+2 >= len(fields) {
+				break
+			}
+			path = fields[i+2]
+			i += 2
+		}
+		stats[path] = stat
+	}
+
+	return stats, nil
+}
+
+// SplitDiff separates `git show` output into the text before the first file
+// (the commit header) and one FileDiff per changed file.
+func SplitDiff(diff string) ([]string, []FileDiff) {
+	var preamble []string
+	var files []FileDiff
+	inHunks := false
+
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		if strings.HasPrefix(line, "diff --git ") || strings.HasPrefix(line, "diff --cc ") {
+			files = append(files, FileDiff{Path: pathFromDiffLine(line)})
+			inHunks = false
+		}
+		if len(files) == 0 {
+			preamble = append(preamble, line)
+			continue
+		}
+
+		current := &files[len(files)-1]
+		current.Lines = append(current.Lines, line)
+
+		switch {
+		case inHunks:
+		case strings.HasPrefix(line, "@@"):
+			inHunks = true
+		case strings.HasPrefix(line, "+++ b/"):
+			current.Path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "--- a/") && current.Path == "":
+			current.Path = strings.TrimPrefix(line, "--- a/")
+		case strings.HasPrefix(line, "rename to "):
+			current.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "copy to "):
+			current.Path = strings.TrimPrefix(line, "copy to ")
+		}
+	}
+
+	return preamble, files
+}
+
+func pathFromDiffLine(line string) string {
+	if strings.HasPrefix(line, "diff --cc ") {
+		return strings.TrimPrefix(line, "diff --cc ")
+	}
+	if idx := strings.LastIndex(line, " b/"); idx != -1 {
+		return line[idx+3:]
+	}
+	return ""
+}
+
+// DiffLine is a single line of a hunk. Kind is '+', '-' or ' ' for context;
+// line numbers are zero when the line does not exist on that side.
+type DiffLine struct {
+	Kind   byte
+	Text   string
+	OldNum int
+	NewNum int
+}
+
+type Hunk struct {
+	Header   string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []DiffLine
+}
+
+// Header returns the lines of the file diff before its first hunk
+// ("diff --git", "index", "---", "+++" and friends).
+func (f FileDiff) Header() []string {
+	for i, line := range f.Lines {
+		if strings.HasPrefix(line, "@@") {
+			return f.Lines[:i]
+		}
+	}
+	return f.Lines
+}
+
+// Hunks parses the file diff into hunks with line numbers on both sides.
+func (f FileDiff) Hunks() []Hunk {
+	var hunks []Hunk
+	var oldNum, newNum int
+
+	for _, line := range f.Lines {
+		if strings.HasPrefix(line, "@@") {
+			hunk := parseHunkHeader(line)
+			hunks = append(hunks, hunk)
+			oldNum, newNum = hunk.OldStart, hunk.NewStart
+			continue
+		}
+		if len(hunks) == 0 {
+			continue
+		}
+
+		current := &hunks[len(hunks)-1]
+		if line == "" {
+			// Some tools strip the trailing space of empty context lines
+			line = " "
+		}
+
+		switch line[0] {
+		case '+':
+			current.Lines = append(current.Lines, DiffLine{Kind: '+', Text: line[1:], NewNum: newNum})
+			newNum++
+		case '-':
+			current.Lines = append(current.Lines, DiffLine{Kind: '-', Text: line[1:], OldNum: oldNum})
+			oldNum++
+		case ' ':
+			current.Lines = append(current.Lines, DiffLine{Kind: ' ', Text: line[1:], OldNum: oldNum, NewNum: newNum})
+			oldNum++
+			newNum++
+		case '\\':
+			// "\ No newline at end of file"
+			current.Lines = append(current.Lines, DiffLine{Kind: '\\', Text: line})
+		}
+	}
+
+	return hunks
+}
+
+// parseHunkHeader reads "@@ -a,b +c,d @@ context" into a Hunk.
+func parseHunkHeader(line string) Hunk {
+	hunk := Hunk{Header: line, OldLines: 1, NewLines: 1}
+
+	fields := strings.Fields(line)
+	for _, field := range fields[1:] {
+		if field == "@@" {
+			break
+		}
+
+		start, count, hasCount := strings.Cut(field[1:], ",")
+		startNum, _ := strconv.Atoi(start)
+		countNum := 1
+		if hasCount {
+			countNum, _ = strconv.Atoi(count)
+		}
+
+		switch field[0] {
+		case '-':
+			hunk.OldStart, hunk.OldLines = startNum, countNum
+		case '+':
+			hunk.NewStart, hunk.NewLines = startNum, countNum
+		}
+	}
+
+	return hunk
+}
+
+// Range is a half-open byte range within a line.
+type Range struct {
+	Start int
+	End   int
+}
+
+// maxWordDiffTokens bounds the quadratic word diff; longer lines are treated
+// as changed as a whole.
+const maxWordDiffTokens = 300
+
+// WordDiff compares a removed line with the added line that replaced it and
+// returns the parts of each that actually changed.
+func WordDiff(oldLine, newLine string) ([]Range, []Range) {
+	oldTokens := splitWords(oldLine)
+	newTokens := splitWords(newLine)
+	if len(oldTokens) > maxWordDiffTokens || len(newTokens) > maxWordDiffTokens {
+		return []Range{{0, len(oldLine)}}, []Range{{0, len(newLine)}}
+	}
+
+	// Longest common subsequence over the tokens
+	lcs := make([][]int, len(oldTokens)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newTokens)+1)
+	}
+	for i := len(oldTokens) - 1; i >= 0; i-- {
+		for j := len(newTokens) - 1; j >= 0; j-- {
+			if oldTokens[i].text == newTokens[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var oldChanged, newChanged []Range
+	i, j := 0, 0
+	for i < len(oldTokens) || j < len(newTokens) {
+		switch {
+		case i < len(oldTokens) && j < len(newTokens) && oldTokens[i].text == newTokens[j].text:
+			i++
+			j++
+		case j < len(newTokens) && (i == len(oldTokens) || lcs[i][j+1] >= lcs[i+1][j]):
+			newChanged = appendRange(newChanged, newTokens[j].start, newTokens[j].start+len(newTokens[j].text))
+			j++
+		default:
+			oldChanged = appendRange(oldChanged, oldTokens[i].start, oldTokens[i].start+len(oldTokens[i].text))
+			i++
+		}
+	}
+
+	return oldChanged, newChanged
+}
+
+type wordToken struct {
+	text  string
+	start int
+}
+
+// splitWords breaks a line into identifier/number runs, whitespace runs and
+// single punctuation characters.
+func splitWords(line string) []wordToken {
+	var tokens []wordToken
+	i := 0
+	for i < len(line) {
+		j := i + 1
+		switch {
+		case isWordByte(line[i]):
+			for j < len(line) && isWordByte(line[j]) {
+				j++
+			}
+		case line[i] == ' ' || line[i] == '\t':
+			for j < len(line) && (line[j] == ' ' || line[j] == '\t') {
+				j++
+			}
+		}
+		tokens = append(tokens, wordToken{text: line[i:j], start: i})
+		i = j
+	}
+	return tokens
+}
+
+func appendRange(ranges []Range, start, end int) []Range {
+	if n := len(ranges); n > 0 && ranges[n-1].End == start {
+		ranges[n-1].End = end
+		return ranges
+	}
+	return append(ranges, Range{Start: start, End: end})
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var sampleShow = strings.Join([]string{
+	"commit 6212b2ae2b65edeba18aabe8e8938b81bfb18b19",
+	"Author: Bob <bob@example.com>",
+	"",
+	"    change two",
+	"",
+	"diff --git a/a.txt b/a.txt",
+	"index 5626abf..814f4a4 100644",
+	"--- a/a.txt",
+	"+++ b/a.txt",
+	"@@ -1,3 +1,3 @@",
+	" one",
+	"-two",
+	"+TWO",
+	" three",
+	"@@ -10 +10,2 @@ func main() {",
+	" ten",
+	"+eleven",
+	"\\ No newline at end of file",
+	"diff --git a/old.txt b/new.txt",
+	"similarity index 100%",
+	"rename from old.txt",
+	"rename to new.txt",
+	"diff --git a/gone.txt b/gone.txt",
+	"deleted file mode 100644",
+	"--- a/gone.txt",
+	"+++ /dev/null",
+	"@@ -1 +0,0 @@",
+	"-gone",
+	"",
+}, "\n")
+
+func TestSplitDiff(t *testing.T) {
+	preamble, files := SplitDiff(sampleShow)
+	if len(preamble) != 5 || preamble[0] != "commit 6212b2ae2b65edeba18aabe8e8938b81bfb18b19" {
+		t.Errorf("preamble = %q", preamble)
+	}
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	if want := []string{"a.txt", "new.txt", "gone.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+	if header := files[0].Header(); len(header) != 4 || header[3] != "+++ b/a.txt" {
+		t.Errorf("header = %q", header)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	_, files := SplitDiff(sampleShow)
+
+	hunks := files[0].Hunks()
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(hunks))
+	}
+	first := hunks[0]
+	if first.OldStart != 1 || first.OldLines != 3 || first.NewStart != 1 || first.NewLines != 3 {
+		t.Errorf("first hunk range = -%d,%d +%d,%d", first.OldStart, first.OldLines, first.NewStart, first.NewLines)
+	}
+	wantLines := []DiffLine{
+		{Kind: ' ', Text: "one", OldNum: 1, NewNum: 1},
+		{Kind: '-', Text: "two", OldNum: 2},
+		{Kind: '+', Text: "TWO", NewNum: 2},
+		{Kind: ' ', Text: "three", OldNum: 3, NewNum: 3},
+	}
+	if !reflect.DeepEqual(first.Lines, wantLines) {
+		t.Errorf("first hunk lines = %+v, want %+v", first.Lines, wantLines)
+	}
+
+	// A range without a count covers one line
+	second := hunks[1]
+	if second.OldStart != 10 || second.OldLines != 1 || second.NewStart != 10 || second.NewLines != 2 {
+		t.Errorf("second hunk range = -%d,%d +%d,%d", second.OldStart, second.OldLines, second.NewStart, second.NewLines)
+	}
+	wantLines = []DiffLine{
+		{Kind: ' ', Text: "ten", OldNum: 10, NewNum: 10},
+		{Kind: '+', Text: "eleven", NewNum: 11},
+		{Kind: '\\', Text: "\\ No newline at end of file"},
+	}
+	if !reflect.DeepEqual(second.Lines, wantLines) {
+		t.Errorf("second hunk lines = %+v, want %+v", second.Lines, wantLines)
+	}
+
+	if hunks := files[1].Hunks(); len(hunks) != 0 {
+		t.Errorf("pure rename has %d hunks", len(hunks))
+	}
+	deleted := files[2].Hunks()
+	if len(deleted) != 1 || deleted[0].NewStart != 0 || deleted[0].NewLines != 0 || deleted[0].Lines[0].OldNum != 1 {
+		t.Errorf("deleted file hunks = %+v", deleted)
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		old, new         string
+		wantOld, wantNew []Range
+	}{
+		{"same", "same", nil, nil},
+		{"return a + b", "return a - b", []Range{{9, 10}}, []Range{{9, 10}}},
+		{"x := foo(1)", "x := fooBar(1, 2)", []Range{{5, 8}}, []Range{{5, 11}, {13, 16}}},
+		{"", "added", nil, []Range{{0, 5}}},
+		{"gone", "", []Range{{0, 4}}, nil},
+	}
+	for _, tt := range tests {
+		gotOld, gotNew := WordDiff(tt.old, tt.new)
+		if !reflect.DeepEqual(gotOld, tt.wantOld) || !reflect.DeepEqual(gotNew, tt.wantNew) {
+			t.Errorf("WordDiff(%q, %q) = %v, %v, want %v, %v", tt.old, tt.new, gotOld, gotNew, tt.wantOld, tt.wantNew)
+		}
+	}
+
+	long := strings.Repeat("a ", maxWordDiffTokens)
+	gotOld, gotNew := WordDiff(long, long+"b")
+	if !reflect.DeepEqual(gotOld, []Range{{0, len(long)}}) || !reflect.DeepEqual(gotNew, []Range{{0, len(long) + 1}}) {
+		t.Errorf("long lines are not marked changed as a whole: %v, %v", gotOld, gotNew)
+	}
+}
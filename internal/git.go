@@ -1,7 +1,12 @@
 package internal
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,6 +20,14 @@ type Branch struct {
 	CommitsAhead      int
 	CommitsBehind     int
 	Selected          bool
+	// Worktree is the path of another worktree that has the branch checked
+	// out, which keeps it from being checked out here.
+	Worktree string
+	Hash     string
+	// Upstream is the branch's configured upstream such as origin/main;
+	// UpstreamGone is set when that remote branch was deleted.
+	Upstream     string
+	UpstreamGone bool
 }
 
 type Commit struct {
@@ -23,14 +36,51 @@ type Commit struct {
 	Date    time.Time
 	Author  string
 	Diff    string
+	Parents []string
+	Refs    []string
+
+	// Filled in by GetCommitDetails only; they are too costly for a whole log
+	Body           string
+	AuthorEmail    string
+	Committer      string
+	CommitterEmail string
+	CommitDate     time.Time
+	Trailers       []Trailer
+	Signature      Signature
+}
+
+// Trailer is a "Key: value" line at the end of a commit message, such as
+// Co-authored-by or Signed-off-by.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// Signature is the GPG/SSH signature check of a commit. Status is git's %G?
+// code: G good, B bad, U good with unknown validity, X/Y expired signature/key,
+// R revoked key, E cannot be checked, N unsigned.
+type Signature struct {
+	Status string
+	Signer string
+	Key    string
+}
+
+func (s Signature) Signed() bool {
+	return s.Status != "" && s.Status != "N"
+}
+
+func (s Signature) Valid() bool {
+	return s.Status == "G" || s.Status == "U"
 }
 
 func GetBranches() ([]Branch, error) {
-	cmd := exec.Command("git", "branch", "-v", "--format=%(refname:short)|%(committerdate:iso)|%(subject)")
+	cmd := gitCommand("branch", "-v", "--format=%(refname:short)|%(committerdate:iso)|%(worktreepath)|%(objectname)|%(upstream:short)|%(upstream:track)|%(subject)")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
+	root, _ := RepositoryRoot()
+	remote := DefaultRemote()
 
 	var branches []Branch
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
@@ -40,8 +90,8 @@ func GetBranches() ([]Branch, error) {
 			continue
 		}
 
-		parts := strings.SplitN(line, "|", 3)
-		if len(parts) != 3 {
+		parts := strings.SplitN(line, "|", 7)
+		if len(parts) != 7 {
 			continue
 		}
 
@@ -56,9 +106,21 @@ func GetBranches() ([]Branch, error) {
 			commitDate = time.Now()
 		}
 
-		commitMsg := strings.TrimSpace(parts[2])
+		commitMsg := strings.TrimSpace(parts[6])
+		upstream := parts[4]
+		upstreamGone := parts[5] == "[gone]"
+
+		worktree := parts[2]
+		if samePath(worktree, root) {
+			worktree = ""
+		}
 
-		ahead, behind := getBranchStatus(branchName)
+		// Without an upstream the branch is compared with its namesake on the default remote
+		compareTo := upstream
+		if compareTo == "" {
+			compareTo = remote + "/" + branchName
+		}
+		ahead, behind := getBranchStatus(branchName, compareTo)
 
 		branches = append(branches, Branch{
 			Name:              branchName,
@@ -67,6 +129,10 @@ func GetBranches() ([]Branch, error) {
 			CommitsAhead:      ahead,
 			CommitsBehind:     behind,
 			Selected:          false,
+			Worktree:          worktree,
+			Hash:              parts[3],
+			Upstream:          upstream,
+			UpstreamGone:      upstreamGone,
 		})
 	}
 
@@ -77,8 +143,9 @@ func GetBranches() ([]Branch, error) {
 	return branches, nil
 }
 
-func getBranchStatus(branchName string) (ahead, behind int) {
-	cmd := exec.Command("git", "rev-list", "--left-right", "--count", "origin/"+branchName+"..."+branchName)
+// getBranchStatus counts the commits a branch is ahead of and behind upstream.
+func getBranchStatus(branchName, upstream string) (ahead, behind int) {
+	cmd := gitCommand("rev-list", "--left-right", "--count", upstream+"..."+branchName)
 	output, err := cmd.Output()
 	if err != nil {
 		return 0, 0
@@ -94,15 +161,15 @@ func getBranchStatus(branchName string) (ahead, behind int) {
 }
 
 func CheckoutBranch(branchName string) error {
-	cmd := exec.Command("git", "checkout", branchName)
+	cmd := gitCommand("checkout", branchName)
 	return cmd.Run()
 }
 
 func DeleteBranches(branches []string) error {
 	for _, branch := range branches {
-		cmd := exec.Command("git", "branch", "-d", branch)
+		cmd := gitCommand("branch", "-d", branch)
 		if err := cmd.Run(); err != nil {
-			cmd = exec.Command("git", "branch", "-D", branch)
+			cmd = gitCommand("branch", "-D", branch)
 			if err := cmd.Run(); err != nil {
 				return err
 			}
@@ -112,7 +179,31 @@ func DeleteBranches(branches []string) error {
 }
 
 func GetCommits() ([]Commit, error) {
-	cmd := exec.Command("git", "log", "--pretty=format:%H|%s|%ad|%an", "--date=iso")
+	return getCommits()
+}
+
+// GetCommitRange returns the commits reachable from HEAD but not from base,
+// newest first. The range feeds a todo of plain picks, which cannot replay a
+// merge, so ranges containing merges are refused.
+func GetCommitRange(base string) ([]Commit, error) {
+	commits, err := getCommits(base + "..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			return nil, fmt.Errorf("%s..HEAD contains merge commit %s (%s); rebase it with git rebase --rebase-merges instead", base, c.Hash[:8], c.Message)
+		}
+	}
+	return commits, nil
+}
+
+// commitLogFormat is the `git log` format read by parseCommitLine.
+const commitLogFormat = "%H|%P|%D|%ad|%an|%s"
+
+func getCommits(revs ...string) ([]Commit, error) {
+	args := append([]string{"log", "--topo-order", "--pretty=format:" + commitLogFormat, "--date=iso"}, revs...)
+	cmd := gitCommand(args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -122,38 +213,151 @@ func GetCommits() ([]Commit, error) {
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 
 	for _, line := range lines {
-		if line == "" {
-			continue
+		if commit, ok := parseCommitLine(line); ok {
+			commits = append(commits, commit)
 		}
+	}
 
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) != 4 {
-			continue
+	return commits, nil
+}
+
+func parseCommitLine(line string) (Commit, bool) {
+	if line == "" {
+		return Commit{}, false
+	}
+
+	parts := strings.SplitN(line, "|", 6)
+	if len(parts) != 6 {
+		return Commit{}, false
+	}
+
+	hash := parts[0]
+	parents := strings.Fields(parts[1])
+	refs := parseRefs(parts[2])
+	dateStr := parts[3]
+	author := parts[4]
+	message := parts[5]
+
+	commitDate, err := time.Parse("2006-01-02 15:04:05 -0700", dateStr)
+	if err != nil {
+		commitDate = time.Now()
+	}
+
+	return Commit{
+		Hash:    hash,
+		Message: message,
+		Date:    commitDate,
+		Author:  author,
+		Parents: parents,
+		Refs:    refs,
+	}, true
+}
+
+// parseRefs splits a %D decoration ("HEAD -> main, origin/main, tag: v1.0")
+// into individual ref names.
+func parseRefs(decoration string) []string {
+	var refs []string
+	for _, ref := range strings.Split(decoration, ",") {
+		ref = strings.TrimSpace(ref)
+		if ref != "" {
+			refs = append(refs, ref)
 		}
+	}
+	return refs
+}
 
-		hash := parts[0]
-		message := parts[1]
-		dateStr := parts[2]
-		author := parts[3]
+// GetCommitDetails loads everything about a single commit: full message,
+// trailers, author and committer, parents and signature status.
+func GetCommitDetails(hash string) (*Commit, error) {
+	fields := []string{"%H", "%P", "%D", "%an", "%ae", "%ad", "%cn", "%ce", "%cd", "%G?", "%GS", "%GK", "%s", "%b", "%(trailers:only,unfold)"}
+	cmd := gitCommand("log", "-1", "--date=iso", "--format="+strings.Join(fields, "%x00"), hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
 
-		commitDate, err := time.Parse("2006-01-02 15:04:05 -0700", dateStr)
-		if err != nil {
-			commitDate = time.Now()
+	parts := strings.Split(strings.TrimRight(string(output), "\n"), "\x00")
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("unexpected git log output for %s", hash)
+	}
+
+	authorDate, err := time.Parse("2006-01-02 15:04:05 -0700", parts[5])
+	if err != nil {
+		authorDate = time.Now()
+	}
+	commitDate, err := time.Parse("2006-01-02 15:04:05 -0700", parts[8])
+	if err != nil {
+		commitDate = authorDate
+	}
+
+	commit := &Commit{
+		Hash:           parts[0],
+		Parents:        strings.Fields(parts[1]),
+		Refs:           parseRefs(parts[2]),
+		Author:         parts[3],
+		AuthorEmail:    parts[4],
+		Date:           authorDate,
+		Committer:      parts[6],
+		CommitterEmail: parts[7],
+		CommitDate:     commitDate,
+		Signature: Signature{
+			Status: parts[9],
+			Signer: parts[10],
+			Key:    parts[11],
+		},
+		Message:  parts[12],
+		Trailers: parseTrailers(parts[14]),
+	}
+	commit.Body = stripTrailers(strings.TrimSpace(parts[13]), commit.Trailers)
+
+	return commit, nil
+}
+
+func parseTrailers(text string) []Trailer {
+	var trailers []Trailer
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
 		}
+		trailers = append(trailers, Trailer{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return trailers
+}
 
-		commits = append(commits, Commit{
-			Hash:    hash,
-			Message: message,
-			Date:    commitDate,
-			Author:  author,
-		})
+// stripTrailers removes the trailer block from the end of a message body so
+// it is not shown twice.
+func stripTrailers(body string, trailers []Trailer) string {
+	if len(trailers) == 0 {
+		return body
 	}
 
-	return commits, nil
+	lines := strings.Split(body, "\n")
+	end := len(lines)
+	for end > 0 {
+		line := strings.TrimSpace(lines[end-1])
+		key, _, ok := strings.Cut(line, ":")
+		isTrailer := false
+		for _, trailer := range trailers {
+			if ok && strings.EqualFold(strings.TrimSpace(key), trailer.Key) {
+				isTrailer = true
+				break
+			}
+		}
+		// Folded trailer values continue on indented lines
+		if !isTrailer && !strings.HasPrefix(lines[end-1], " ") {
+			break
+		}
+		end--
+	}
+
+	return strings.TrimSpace(strings.Join(lines[:end], "\n"))
 }
 
+// GetCommitDiff returns the patch of a commit (against its first parent for
+// merges) without the commit header, which GetCommitDetails provides.
 func GetCommitDiff(hash string) (string, error) {
-	cmd := exec.Command("git", "show", "--format=fuller", hash)
+	cmd := gitCommand("show", "--format=", "--diff-merges=first-parent", hash)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -167,6 +371,12 @@ type MergeResult struct {
 	ConflictFiles []string
 	ConflictCount int
 	ErrorMessage  string
+	// Output is what the git command printed
+	Output string
+	// ReturnTo is the branch a cherry-pick onto another branch started from.
+	// It is set when the pick stopped on conflicts and left the working tree
+	// on the target branch.
+	ReturnTo string
 }
 
 func MergeBranch(sourceBranch, targetBranch string) (*MergeResult, error) {
@@ -179,73 +389,383 @@ func MergeBranch(sourceBranch, targetBranch string) (*MergeResult, error) {
 	}
 
 	// Attempt the merge
-	cmd := exec.Command("git", "merge", sourceBranch)
+	cmd := gitCommand("merge", sourceBranch)
 	output, err := cmd.CombinedOutput()
 
-	result := &MergeResult{}
+	return resultFromOutput(output, err), nil
+}
+
+// resultFromOutput turns the outcome of a merge-like git command into a
+// MergeResult, telling conflicts apart from other failures.
+func resultFromOutput(output []byte, err error) *MergeResult {
+	result := &MergeResult{Output: strings.TrimSpace(string(output))}
 
 	if err != nil {
 		// Check if it's a merge conflict
-		statusCmd := exec.Command("git", "status", "--porcelain")
-		statusOutput, statusErr := statusCmd.Output()
-		if statusErr == nil {
-			conflictFiles := []string{}
-			lines := strings.Split(strings.TrimSpace(string(statusOutput)), "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "UU ") || strings.HasPrefix(line, "AA ") ||
-					strings.HasPrefix(line, "DD ") || strings.Contains(line, "U") {
-					// Extract filename (skip status indicators)
-					if len(line) > 3 {
-						conflictFiles = append(conflictFiles, strings.TrimSpace(line[3:]))
-					}
-				}
-			}
-
-			if len(conflictFiles) > 0 {
-				result.HasConflicts = true
-				result.ConflictFiles = conflictFiles
-				result.ConflictCount = len(conflictFiles)
-				result.Success = false
-			} else {
-				result.Success = false
-				result.ErrorMessage = string(output)
-			}
+		conflictFiles, statusErr := GetConflictFiles()
+		if statusErr == nil && len(conflictFiles) > 0 {
+			result.HasConflicts = true
+			result.ConflictFiles = conflictFiles
+			result.ConflictCount = len(conflictFiles)
+			result.Success = false
 		} else {
 			result.Success = false
-			result.ErrorMessage = string(output)
+			result.ErrorMessage = strings.TrimSpace(string(output))
 		}
 	} else {
 		result.Success = true
 	}
 
-	return result, nil
+	return result
+}
+
+// GetConflictFiles lists the files with unresolved conflicts.
+func GetConflictFiles() ([]string, error) {
+	entries, err := GetStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	conflictFiles := []string{}
+	for _, entry := range entries {
+		if entry.Conflicted() {
+			conflictFiles = append(conflictFiles, entry.Path)
+		}
+	}
+
+	return conflictFiles, nil
 }
 
 func AbortMerge() error {
-	cmd := exec.Command("git", "merge", "--abort")
+	cmd := gitCommand("merge", "--abort")
+	return cmd.Run()
+}
+
+// CherryPick applies commits (oldest first) onto targetBranch, or onto the
+// current branch when targetBranch is empty. After a pick onto another branch
+// the original branch is checked out again, unless the pick stopped on
+// conflicts: then it stays on targetBranch and records the original in
+// ReturnTo.
+func CherryPick(hashes []string, targetBranch string) (*MergeResult, error) {
+	var original string
+	if targetBranch != "" {
+		// A detached HEAD is returned to by its commit
+		var err error
+		if original, err = CurrentBranch(); err != nil {
+			if original, err = revParse("HEAD"); err != nil {
+				return nil, err
+			}
+		}
+		if err := CheckoutBranch(targetBranch); err != nil {
+			return &MergeResult{
+				Success:      false,
+				ErrorMessage: "Failed to checkout target branch: " + err.Error(),
+			}, err
+		}
+	}
+
+	args := append([]string{"cherry-pick"}, hashes...)
+	cmd := gitCommand(args...)
+	output, err := cmd.CombinedOutput()
+
+	result := resultFromOutput(output, err)
+	if original == "" {
+		return result, nil
+	}
+	// A stopped pick stays on the target to be resolved there; anything else
+	// goes back to where it started
+	if !result.Success && SequenceInProgress() {
+		result.ReturnTo = original
+		return result, nil
+	}
+	if err := CheckoutBranch(original); err != nil {
+		message := fmt.Sprintf("checking out %s again failed: %v", original, err)
+		if !result.Success {
+			message = result.ErrorMessage + "; " + message
+		}
+		result.Success = false
+		result.ErrorMessage = message
+	}
+	return result, nil
+}
+
+// RevertCommits reverts commits (newest first) on the current branch.
+func RevertCommits(hashes []string) (*MergeResult, error) {
+	args := append([]string{"revert", "--no-edit"}, hashes...)
+	cmd := gitCommand(args...)
+	output, err := cmd.CombinedOutput()
+
+	return resultFromOutput(output, err), nil
+}
+
+// ContinueSequence resumes a cherry-pick or revert after conflicts have been
+// resolved, keeping the prepared commit messages.
+func ContinueSequence(operation string) (*MergeResult, error) {
+	if result, err := stageResolvedFiles(); err != nil || result != nil {
+		return result, err
+	}
+
+	cmd := gitCommand("-c", "core.editor=true", operation, "--continue")
+	output, err := cmd.CombinedOutput()
+
+	return resultFromOutput(output, err), nil
+}
+
+// stageResolvedFiles adds conflicted files that no longer contain conflict
+// markers. It returns a conflict result when some are still unresolved.
+func stageResolvedFiles() (*MergeResult, error) {
+	conflictFiles, err := GetConflictFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(conflictFiles) == 0 {
+		return nil, nil
+	}
+	// Status paths are relative to the top of the worktree
+	root, err := RepositoryRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	var unresolved []string
+	for _, file := range conflictFiles {
+		content, err := os.ReadFile(filepath.Join(root, file))
+		if err == nil && bytes.Contains(content, []byte("<<<<<<< ")) {
+			unresolved = append(unresolved, file)
+			continue
+		}
+
+		// Deleted files are resolved by removing them from the index
+		addCmd := gitCommand("add", "-A", "--", ":(literal)"+file)
+		addCmd.Dir = root
+		if err := addCmd.Run(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return &MergeResult{
+			HasConflicts:  true,
+			ConflictFiles: unresolved,
+			ConflictCount: len(unresolved),
+		}, nil
+	}
+	return nil, nil
+}
+
+// SequenceInProgress reports whether a cherry-pick or revert has stopped and
+// waits to be continued or aborted.
+func SequenceInProgress() bool {
+	for _, name := range []string{"CHERRY_PICK_HEAD", "REVERT_HEAD", "sequencer"} {
+		path, err := gitPath(name)
+		if err != nil {
+			return false
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// AbortSequence aborts an in-progress cherry-pick or revert.
+func AbortSequence(operation string) error {
+	cmd := gitCommand(operation, "--abort")
 	return cmd.Run()
 }
 
-func StageAllAndAmend(push bool) error {
+type AmendOptions struct {
+	// StageAll runs `git add -A` first; otherwise only what is already
+	// staged goes into the amended commit
+	StageAll bool
+	// Message replaces the commit message when set
+	Message string
+	// Edit opens the editor on the current message
+	Edit bool
+	// Push force-pushes the result to the branch's upstream with a lease
+	Push bool
+}
+
+func AmendCommit(opts AmendOptions) error {
+	// Resolve where to push before rewriting anything, so the lease is taken
+	// from the remote tip we knew about when the amend started
+	var target *PushTarget
+	if opts.Push {
+		var err error
+		if target, err = GetPushTarget(); err != nil {
+			return err
+		}
+	}
+
 	// Stage all changes
-	addCmd := exec.Command("git", "add", "-A")
-	if err := addCmd.Run(); err != nil {
-		return err
+	if opts.StageAll {
+		addCmd := gitCommand("add", "-A")
+		if err := addCmd.Run(); err != nil {
+			return err
+		}
 	}
 
-	// Amend the commit with the same message
-	amendCmd := exec.Command("git", "commit", "--amend", "--no-edit")
-	if err := amendCmd.Run(); err != nil {
+	// Amend the commit, keeping the message unless asked otherwise
+	args := []string{"commit", "--amend"}
+	switch {
+	case opts.Message != "":
+		args = append(args, "-m", opts.Message)
+	case !opts.Edit:
+		args = append(args, "--no-edit")
+	}
+	amendCmd := gitCommand(args...)
+	if opts.Edit && opts.Message == "" {
+		// The editor needs the terminal
+		amendCmd.Stdin = os.Stdin
+		amendCmd.Stdout = os.Stdout
+		amendCmd.Stderr = os.Stderr
+		if err := amendCmd.Run(); err != nil {
+			return err
+		}
+	} else if err := runWithOutput(amendCmd); err != nil {
 		return err
 	}
 
 	// Push if requested
-	if push {
-		pushCmd := exec.Command("git", "push", "origin", "HEAD", "-f")
-		if err := pushCmd.Run(); err != nil {
-			return err
+	if opts.Push {
+		if err := ForcePush(target); err != nil {
+			return fmt.Errorf("commit amended but not pushed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateCommit commits the staged changes with the given message.
+func CreateCommit(message string) error {
+	messageFile, err := os.CreateTemp("", "smak-message-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(messageFile.Name())
+	if _, err := messageFile.WriteString(message + "\n"); err != nil {
+		messageFile.Close()
+		return err
+	}
+	messageFile.Close()
+
+	return runWithOutput(gitCommand("commit", "-F", messageFile.Name()))
+}
+
+// GetConfig reads a git config value, returning "" when it is not set.
+func GetConfig(key string) string {
+	cmd := gitCommand("config", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func revParse(rev string) (string, error) {
+	cmd := gitCommand("rev-parse", "--verify", rev)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ResolveCommit turns a revision (hash, branch, HEAD~2, ...) into a full
+// commit hash.
+func ResolveCommit(rev string) (string, error) {
+	hash, err := revParse(rev + "^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return hash, nil
+}
+
+// runWithOutput runs a command and folds its output into the error.
+func runWithOutput(cmd *exec.Cmd) error {
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if text := strings.TrimSpace(string(output)); text != "" {
+			return fmt.Errorf("%w: %s", err, text)
 		}
+		return err
 	}
+	return nil
+}
 
+// RunWithProgress runs a command and passes each line it prints to progress
+// as it arrives. Git redraws its progress counters with carriage returns,
+// so those end a line too. The error includes git's error and rejection
+// lines, or the last line printed when there are none.
+func RunWithProgress(cmd *exec.Cmd, progress func(string)) error {
+	finish, err := StartWithProgress(cmd)
+	if err != nil {
+		return err
+	}
+	return finish(progress)
+}
+
+// StartWithProgress starts cmd the way RunWithProgress runs it and returns
+// as soon as it is running. finish passes the command's output to progress
+// and waits for it to exit.
+func StartWithProgress(cmd *exec.Cmd) (finish func(progress func(string)) error, err error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		writer.Close()
+		reader.Close()
+		return nil, err
+	}
+	// Only the child keeps the write end open, so reading stops when it exits
+	writer.Close()
+
+	return func(progress func(string)) error {
+		return streamProgress(cmd, reader, progress)
+	}, nil
+}
+
+func streamProgress(cmd *exec.Cmd, reader *os.File, progress func(string)) error {
+	var last string
+	var problems []string
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		last = line
+		if strings.HasPrefix(line, "!") || strings.HasPrefix(line, "error:") || strings.HasPrefix(line, "fatal:") {
+			problems = append(problems, strings.Join(strings.Fields(line), " "))
+		}
+		progress(line)
+	}
+	reader.Close()
+
+	if err := cmd.Wait(); err != nil {
+		if len(problems) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.Join(problems, "; "))
+		}
+		if last != "" {
+			return fmt.Errorf("%w: %s", err, last)
+		}
+		return err
+	}
 	return nil
 }
+
+// scanProgressLines is bufio.ScanLines that also splits on '\r'.
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,195 @@
+package internal
+
+import "strings"
+
+// GraphRow holds the two lines of graph drawn next to a commit: the line with
+// the commit node itself and the connector line leading to the next commit.
+type GraphRow struct {
+	Node string
+	Link string
+}
+
+// BuildGraph lays out commits (in topological order, newest first) into lanes
+// the same way `git log --graph` does and returns one row per commit.
+func BuildGraph(commits []Commit) []GraphRow {
+	rows := make([]GraphRow, len(commits))
+	var lanes []string
+
+	for idx, commit := range commits {
+		col := -1
+		for i, hash := range lanes {
+			if hash == commit.Hash {
+				col = i
+				break
+			}
+		}
+		if col == -1 {
+			col = firstFreeLane(lanes)
+			if col == len(lanes) {
+				lanes = append(lanes, commit.Hash)
+			} else {
+				lanes[col] = commit.Hash
+			}
+		}
+
+		// Node line: other lanes waiting for this commit converge into it
+		node := newGraphLine(len(lanes))
+		for i, hash := range lanes {
+			if hash != "" {
+				node.set(i, '│')
+			}
+		}
+		for i, hash := range lanes {
+			if i == col || hash != commit.Hash {
+				continue
+			}
+			if i > col {
+				node.join(col, i, '┘')
+			} else {
+				node.join(i, col, '└')
+			}
+			lanes[i] = ""
+		}
+		node.set(col, '●')
+
+		// Link line: first parent continues the lane, the others branch off
+		if len(commit.Parents) > 0 {
+			lanes[col] = commit.Parents[0]
+		} else {
+			lanes[col] = ""
+		}
+
+		type edge struct {
+			lane    int
+			created bool
+		}
+		var edges []edge
+		for _, parent := range commit.Parents[min(1, len(commit.Parents)):] {
+			target := -1
+			for i, hash := range lanes {
+				if hash == parent {
+					target = i
+					break
+				}
+			}
+			created := false
+			if target == -1 {
+				target = firstFreeLane(lanes)
+				if target == len(lanes) {
+					lanes = append(lanes, parent)
+				} else {
+					lanes[target] = parent
+				}
+				created = true
+			}
+			edges = append(edges, edge{lane: target, created: created})
+		}
+
+		link := newGraphLine(len(lanes))
+		for i, hash := range lanes {
+			if hash != "" {
+				link.set(i, '│')
+			}
+		}
+		left, right := false, false
+		for _, e := range edges {
+			if e.lane == col {
+				continue
+			}
+			if e.lane > col {
+				right = true
+				if e.created {
+					link.join(col, e.lane, '╮')
+				} else {
+					link.join(col, e.lane, '┤')
+				}
+			} else {
+				left = true
+				if e.created {
+					link.join(e.lane, col, '╭')
+				} else {
+					link.join(e.lane, col, '├')
+				}
+			}
+		}
+		switch {
+		case left && right:
+			link.set(col, '┼')
+		case right:
+			link.set(col, '├')
+		case left:
+			link.set(col, '┤')
+		}
+
+		for len(lanes) > 0 && lanes[len(lanes)-1] == "" {
+			lanes = lanes[:len(lanes)-1]
+		}
+
+		rows[idx] = GraphRow{Node: node.String(), Link: link.String()}
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len([]rune(row.Node)), len([]rune(row.Link)))
+	}
+	for i := range rows {
+		rows[i].Node = padGraph(rows[i].Node, width)
+		rows[i].Link = padGraph(rows[i].Link, width)
+	}
+
+	return rows
+}
+
+func firstFreeLane(lanes []string) int {
+	for i, hash := range lanes {
+		if hash == "" {
+			return i
+		}
+	}
+	return len(lanes)
+}
+
+func padGraph(line string, width int) string {
+	if n := len([]rune(line)); n < width {
+		return line + strings.Repeat(" ", width-n)
+	}
+	return line
+}
+
+// graphLine is a row of lane cells, each two runes wide (lane + gap).
+type graphLine []rune
+
+func newGraphLine(lanes int) graphLine {
+	line := make(graphLine, lanes*2)
+	for i := range line {
+		line[i] = ' '
+	}
+	return line
+}
+
+func (l graphLine) set(lane int, r rune) {
+	l[lane*2] = r
+}
+
+// join draws a horizontal edge between two lanes, ending in the given corner.
+// The corner is placed on the lane that is not the commit's own lane.
+func (l graphLine) join(from, to int, corner rune) {
+	for i := from*2 + 1; i < to*2; i++ {
+		switch l[i] {
+		case ' ':
+			l[i] = '─'
+		case '│':
+			l[i] = '┼'
+		}
+	}
+	switch corner {
+	case '┘', '╮', '┤':
+		l[to*2] = corner
+	default:
+		l[from*2] = corner
+	}
+}
+
+func (l graphLine) String() string {
+	return strings.TrimRight(string(l), " ")
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []Commit
+		want    []GraphRow
+	}{
+		{
+			name: "linear",
+			commits: []Commit{
+				{Hash: "c", Parents: []string{"b"}},
+				{Hash: "b", Parents: []string{"a"}},
+				{Hash: "a"},
+			},
+			want: []GraphRow{{"●", "│"}, {"●", "│"}, {"●", " "}},
+		},
+		{
+			name: "merge",
+			commits: []Commit{
+				{Hash: "m", Parents: []string{"a", "b"}},
+				{Hash: "b", Parents: []string{"a"}},
+				{Hash: "a"},
+			},
+			want: []GraphRow{{"●  ", "├─╮"}, {"│ ●", "│ │"}, {"●─┘", "   "}},
+		},
+		{
+			name: "two branch tips",
+			commits: []Commit{
+				{Hash: "x", Parents: []string{"a"}},
+				{Hash: "y", Parents: []string{"a"}},
+				{Hash: "a"},
+			},
+			want: []GraphRow{{"●  ", "│  "}, {"│ ●", "│ │"}, {"●─┘", "   "}},
+		},
+	}
+	for _, tt := range tests {
+		if got := BuildGraph(tt.commits); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: BuildGraph = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
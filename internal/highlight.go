@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+type TokenKind int
+
+const (
+	TokenText TokenKind = iota
+	TokenKeyword
+	TokenString
+	TokenComment
+	TokenNumber
+)
+
+type Token struct {
+	Kind TokenKind
+	Text string
+}
+
+// Language describes just enough of a language's lexical rules to colour a
+// single line of source: keywords, comment markers and string quotes.
+type Language struct {
+	Name         string
+	Keywords     map[string]bool
+	LineComments []string
+	BlockComment [2]string
+	Quotes       string
+}
+
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+var (
+	langGo = &Language{
+		Name: "go",
+		Keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var true false nil iota
+			string int int8 int16 int32 int64 uint uint8 uint16 uint32 uint64 uintptr byte rune bool
+			float32 float64 error any make new len cap append copy delete panic recover`),
+		LineComments: []string{"//"},
+		BlockComment: [2]string{"/*", "*/"},
+		Quotes:       "\"'`",
+	}
+	langPython = &Language{
+		Name: "python",
+		Keywords: words(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda nonlocal not or pass raise return try while with yield
+			None True False self`),
+		LineComments: []string{"#"},
+		Quotes:       "\"'",
+	}
+	langJavaScript = &Language{
+		Name: "javascript",
+		Keywords: words(`async await break case catch class const continue debugger default delete do else
+			export extends finally for from function if import in instanceof let new of return static super
+			switch this throw try typeof var void while with yield null undefined true false
+			interface type enum implements private public protected readonly abstract as`),
+		LineComments: []string{"//"},
+		BlockComment: [2]string{"/*", "*/"},
+		Quotes:       "\"'`",
+	}
+	langRust = &Language{
+		Name: "rust",
+		Keywords: words(`as async await break const continue crate dyn else enum extern false fn for if impl
+			in let loop match mod move mut pub ref return self Self static struct super trait true type
+			unsafe use where while Some None Ok Err`),
+		LineComments: []string{"//"},
+		BlockComment: [2]string{"/*", "*/"},
+		Quotes:       "\"",
+	}
+	langC = &Language{
+		Name: "c",
+		Keywords: words(`auto break case catch char class const continue default delete do double else enum
+			extern final float for goto if import int long namespace new nullptr null package private
+			protected public return short signed sizeof static struct switch template this throw true
+			false try typedef union unsigned using virtual void volatile while boolean extends implements
+			interface var`),
+		LineComments: []string{"//"},
+		BlockComment: [2]string{"/*", "*/"},
+		Quotes:       "\"'",
+	}
+	langRuby = &Language{
+		Name: "ruby",
+		Keywords: words(`alias and begin break case class def do else elsif end ensure false for
+			if in module next nil not or redo rescue retry return self super then true undef unless until
+			when while yield require`),
+		LineComments: []string{"#"},
+		Quotes:       "\"'",
+	}
+	langShell = &Language{
+		Name: "shell",
+		Keywords: words(`if then else elif fi case esac for while until do done in function return
+			local export readonly echo exit set unset`),
+		LineComments: []string{"#"},
+		Quotes:       "\"'",
+	}
+	langYAML = &Language{
+		Name:         "yaml",
+		Keywords:     words(`true false null yes no on off`),
+		LineComments: []string{"#"},
+		Quotes:       "\"'",
+	}
+	langSQL = &Language{
+		Name: "sql",
+		Keywords: words(`select from where insert into values update set delete create table alter drop
+			index join left right inner outer on and or not null as group by order having limit primary
+			key foreign references SELECT FROM WHERE INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE
+			ALTER DROP INDEX JOIN LEFT RIGHT INNER OUTER ON AND OR NOT NULL AS GROUP BY ORDER HAVING
+			LIMIT PRIMARY KEY FOREIGN REFERENCES`),
+		LineComments: []string{"--"},
+		BlockComment: [2]string{"/*", "*/"},
+		Quotes:       "'\"",
+	}
+)
+
+var languagesByExt = map[string]*Language{
+	".go":   langGo,
+	".py":   langPython,
+	".js":   langJavaScript,
+	".jsx":  langJavaScript,
+	".mjs":  langJavaScript,
+	".ts":   langJavaScript,
+	".tsx":  langJavaScript,
+	".json": langJavaScript,
+	".rs":   langRust,
+	".c":    langC,
+	".h":    langC,
+	".cc":   langC,
+	".cpp":  langC,
+	".hpp":  langC,
+	".java": langC,
+	".kt":   langC,
+	".cs":   langC,
+	".rb":   langRuby,
+	".sh":   langShell,
+	".bash": langShell,
+	".zsh":  langShell,
+	".yml":  langYAML,
+	".yaml": langYAML,
+	".toml": langYAML,
+	".sql":  langSQL,
+}
+
+// LanguageForPath picks a language from the file extension, or nil when the
+// file type is unknown.
+func LanguageForPath(path string) *Language {
+	base := filepath.Base(path)
+	switch base {
+	case "Makefile", "Dockerfile", ".bashrc", ".zshrc":
+		return langShell
+	}
+	return languagesByExt[strings.ToLower(filepath.Ext(base))]
+}
+
+// Highlight splits a single line into tokens. Block comments are only
+// recognised when they start on the line itself.
+func Highlight(lang *Language, line string) []Token {
+	if lang == nil {
+		return []Token{{Kind: TokenText, Text: line}}
+	}
+
+	var tokens []Token
+	emit := func(kind TokenKind, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(tokens); n > 0 && tokens[n-1].Kind == kind {
+			tokens[n-1].Text += text
+			return
+		}
+		tokens = append(tokens, Token{Kind: kind, Text: text})
+	}
+
+	// Lines continuing a block comment ("* foo") are common enough to special-case
+	if lang.BlockComment[0] != "" {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, "* ") || trimmed == "*" || strings.HasPrefix(trimmed, "*/") {
+			return []Token{{Kind: TokenComment, Text: line}}
+		}
+	}
+
+	i := 0
+	for i < len(line) {
+		rest := line[i:]
+
+		if lineCommentAt(lang, rest) {
+			emit(TokenComment, rest)
+			break
+		}
+
+		if open := lang.BlockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+			end := strings.Index(rest[len(open):], lang.BlockComment[1])
+			if end == -1 {
+				emit(TokenComment, rest)
+				break
+			}
+			length := len(open) + end + len(lang.BlockComment[1])
+			emit(TokenComment, rest[:length])
+			i += length
+			continue
+		}
+
+		c := line[i]
+		switch {
+		case strings.IndexByte(lang.Quotes, c) != -1:
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(line))
+			emit(TokenString, line[i:j])
+			i = j
+		case isWordByte(c):
+			j := i
+			for j < len(line) && isWordByte(line[j]) {
+				j++
+			}
+			word := line[i:j]
+			switch {
+			case lang.Keywords[word]:
+				emit(TokenKeyword, word)
+			case unicode.IsDigit(rune(word[0])):
+				emit(TokenNumber, word)
+			default:
+				emit(TokenText, word)
+			}
+			i = j
+		default:
+			emit(TokenText, line[i:i+1])
+			i++
+		}
+	}
+
+	return tokens
+}
+
+func lineCommentAt(lang *Language, rest string) bool {
+	for _, marker := range lang.LineComments {
+		if strings.HasPrefix(rest, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
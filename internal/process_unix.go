@@ -0,0 +1,24 @@
+//go:build !windows
+
+package internal
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// ownProcessGroup starts cmd in a process group of its own, so that
+// StopCommand also reaches the commands it runs.
+func ownProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// StopCommand terminates a running command together with everything it
+// started. It does not wait for the command to exit.
+func StopCommand(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return errors.New("the command has not started")
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
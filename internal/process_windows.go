@@ -0,0 +1,21 @@
+//go:build windows
+
+package internal
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// ownProcessGroup is a no-op on Windows, where StopCommand only reaches the
+// command itself.
+func ownProcessGroup(cmd *exec.Cmd) {}
+
+// StopCommand terminates a running command. It does not wait for the
+// command to exit.
+func StopCommand(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return errors.New("the command has not started")
+	}
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// PushTarget is where a branch is pushed, together with the remote tip it
+// was last seen at. Expected is used as the --force-with-lease value and is
+// empty when the branch does not exist on the remote yet.
+type PushTarget struct {
+	Branch      string
+	Remote      string
+	RemoteRef   string
+	Expected    string
+	SetUpstream bool
+}
+
+// LeaseRejectedError is returned when the remote branch moved since it was
+// last fetched, so a forced push would have dropped someone else's commits.
+type LeaseRejectedError struct {
+	Remote string
+	Branch string
+}
+
+func (e *LeaseRejectedError) Error() string {
+	return fmt.Sprintf("push rejected: %s/%s has commits that are not in your local copy; fetch and review them before pushing again", e.Remote, e.Branch)
+}
+
+// CurrentBranch returns the checked out branch, or an error on a detached HEAD.
+func CurrentBranch() (string, error) {
+	cmd := gitCommand("symbolic-ref", "--quiet", "--short", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("not on a branch (detached HEAD)")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetPushTarget resolves the upstream of the current branch. Without an
+// upstream the branch is pushed to the default remote under its own name
+// and the upstream is set by the push. An upstream with another name is
+// refused, see CheckName.
+func GetPushTarget() (*PushTarget, error) {
+	branch, err := CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+	target := GetBranchPushTarget(branch)
+	if err := target.CheckName(); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// GetBranchPushTarget resolves where any local branch is pushed, the same
+// way GetPushTarget does for the current one.
+func GetBranchPushTarget(branch string) *PushTarget {
+	target := &PushTarget{Branch: branch}
+	remote := GetConfig("branch." + branch + ".remote")
+	merge := GetConfig("branch." + branch + ".merge")
+	if remote == "" || remote == "." || merge == "" {
+		target.Remote = DefaultRemote()
+		target.RemoteRef = branch
+		target.SetUpstream = true
+	} else {
+		target.Remote = remote
+		target.RemoteRef = strings.TrimPrefix(merge, "refs/heads/")
+	}
+
+	// The remote-tracking ref is what we last saw of the remote branch
+	if tip, err := revParse("refs/remotes/" + target.Remote + "/" + target.RemoteRef); err == nil {
+		target.Expected = tip
+	}
+
+	return target
+}
+
+// CheckName refuses to push to a remote branch named differently from the
+// local one, as git's push.default=simple does. A branch started from
+// origin/main tracks main, and pushing it there would put its commits on
+// main.
+func (t *PushTarget) CheckName() error {
+	if t.RemoteRef == t.Branch {
+		return nil
+	}
+	return fmt.Errorf("%s tracks %s/%s, which has a different name; smak only pushes a branch to its namesake (git push %s %s:%s pushes there on purpose)",
+		t.Branch, t.Remote, t.RemoteRef, t.Remote, t.Branch, t.RemoteRef)
+}
+
+// ForcePush pushes HEAD to the target, refusing to overwrite commits that
+// arrived on the remote after target.Expected.
+func ForcePush(target *PushTarget) error {
+	if err := target.CheckName(); err != nil {
+		return err
+	}
+	dest := "refs/heads/" + target.RemoteRef
+	args := []string{"push", "--porcelain", "--force-with-lease=" + dest + ":" + target.Expected}
+	if target.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, target.Remote, "HEAD:"+dest)
+
+	cmd := gitCommand(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "stale info") {
+			return &LeaseRejectedError{Remote: target.Remote, Branch: target.RemoteRef}
+		}
+		if text := strings.TrimSpace(string(output)); text != "" {
+			return fmt.Errorf("%w: %s", err, text)
+		}
+		return err
+	}
+	return nil
+}
+
+// PushBranchCommand builds a push of a local branch to its target, setting
+// the upstream when the branch has none. Unlike ForcePush it never
+// overwrites the remote branch, and it reports progress for RunWithProgress.
+// Like ForcePush it refuses an upstream with another name.
+func PushBranchCommand(target *PushTarget) (*exec.Cmd, error) {
+	if err := target.CheckName(); err != nil {
+		return nil, err
+	}
+	args := []string{"push", "--progress"}
+	if target.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, target.Remote, "refs/heads/"+target.Branch+":refs/heads/"+target.RemoteRef)
+	return gitCommand(args...), nil
+}
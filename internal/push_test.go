@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testGit runs git in dir and fails the test when it does.
+func testGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// newTestClone creates a bare repository with one commit on main and a
+// clone of it, and changes into the clone. It returns the bare repository.
+func newTestClone(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	bare := filepath.Join(dir, "remote.git")
+	clone := filepath.Join(dir, "clone")
+	testGit(t, dir, "init", "--bare", "-b", "main", bare)
+	testGit(t, dir, "clone", "-q", bare, clone)
+	testGit(t, clone, "commit", "-q", "--allow-empty", "-m", "initial")
+	testGit(t, clone, "push", "-q", "-u", "origin", "main")
+	t.Chdir(clone)
+	return bare
+}
+
+func runPush(t *testing.T, branch string) error {
+	t.Helper()
+	cmd, err := PushBranchCommand(GetBranchPushTarget(branch))
+	if err != nil {
+		return err
+	}
+	return RunWithProgress(cmd, func(string) {})
+}
+
+func TestPushBranchSetsUpstream(t *testing.T) {
+	bare := newTestClone(t)
+	testGit(t, ".", "checkout", "-q", "-b", "feature")
+	testGit(t, ".", "commit", "-q", "--allow-empty", "-m", "feature work")
+
+	if err := runPush(t, "feature"); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if got, want := testGit(t, bare, "rev-parse", "feature"), testGit(t, ".", "rev-parse", "feature"); got != want {
+		t.Errorf("remote feature at %s, want %s", got, want)
+	}
+	if upstream := testGit(t, ".", "rev-parse", "--abbrev-ref", "feature@{upstream}"); upstream != "origin/feature" {
+		t.Errorf("upstream = %s, want origin/feature", upstream)
+	}
+
+	// Pushing again goes to the upstream that was just set
+	testGit(t, ".", "commit", "-q", "--allow-empty", "-m", "more work")
+	if err := runPush(t, "feature"); err != nil {
+		t.Fatalf("second push: %v", err)
+	}
+	if got, want := testGit(t, bare, "rev-parse", "feature"), testGit(t, ".", "rev-parse", "feature"); got != want {
+		t.Errorf("remote feature at %s after second push, want %s", got, want)
+	}
+}
+
+func TestPushBranchRefusesUpstreamWithOtherName(t *testing.T) {
+	bare := newTestClone(t)
+	mainBefore := testGit(t, bare, "rev-parse", "main")
+	testGit(t, ".", "checkout", "-q", "-b", "feature", "origin/main")
+	testGit(t, ".", "commit", "-q", "--allow-empty", "-m", "unreviewed")
+
+	err := runPush(t, "feature")
+	if err == nil || !strings.Contains(err.Error(), "different name") {
+		t.Fatalf("push error = %v, want a refusal of the differently named upstream", err)
+	}
+	if mainAfter := testGit(t, bare, "rev-parse", "main"); mainAfter != mainBefore {
+		t.Errorf("remote main moved from %s to %s", mainBefore, mainAfter)
+	}
+
+	if err := ForcePush(GetBranchPushTarget("feature")); err == nil {
+		t.Error("force push to a differently named upstream was not refused")
+	}
+	if _, err := GetPushTarget(); err == nil {
+		t.Error("GetPushTarget accepted a differently named upstream")
+	}
+}
+
+func TestPullBranchFastForwards(t *testing.T) {
+	bare := newTestClone(t)
+	testGit(t, ".", "checkout", "-q", "-b", "feature")
+	if err := runPush(t, "feature"); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	testGit(t, ".", "checkout", "-q", "main")
+
+	// Someone else adds a commit to feature
+	other := filepath.Join(t.TempDir(), "other")
+	testGit(t, ".", "clone", "-q", "-b", "feature", bare, other)
+	testGit(t, other, "commit", "-q", "--allow-empty", "-m", "from elsewhere")
+	testGit(t, other, "push", "-q", "origin", "feature")
+	want := testGit(t, other, "rev-parse", "HEAD")
+
+	branches, err := GetBranches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, branch := range branches {
+		if branch.Name != "feature" {
+			continue
+		}
+		// feature is not checked out, so it is updated without switching to it
+		cmd, err := PullBranchCommand(branch, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := RunWithProgress(cmd, func(string) {}); err != nil {
+			t.Fatalf("pull: %v", err)
+		}
+	}
+
+	if got := testGit(t, ".", "rev-parse", "feature"); got != want {
+		t.Errorf("feature at %s after pull, want %s", got, want)
+	}
+	if current := testGit(t, ".", "branch", "--show-current"); current != "main" {
+		t.Errorf("pull switched to %s", current)
+	}
+}
+
+func TestPullBranchWithoutUpstream(t *testing.T) {
+	newTestClone(t)
+	testGit(t, ".", "branch", "local-only")
+	if _, err := PullBranchCommand(Branch{Name: "local-only"}, false); err == nil {
+		t.Error("pulling a branch without upstream was not refused")
+	}
+}
@@ -0,0 +1,315 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RebaseActions are the todo commands smak lets the user choose from.
+var RebaseActions = []string{"pick", "reword", "edit", "squash", "fixup", "drop"}
+
+type RebaseStep struct {
+	Action string
+	Commit Commit
+}
+
+type RebaseState struct {
+	InProgress    bool
+	Step          int
+	Total         int
+	StoppedAt     string
+	ConflictFiles []string
+}
+
+// WriteRebaseTodo writes steps (oldest first) as a rebase todo list to a
+// temporary file and returns its path.
+func WriteRebaseTodo(steps []RebaseStep) (string, error) {
+	var b strings.Builder
+	for _, step := range steps {
+		fmt.Fprintf(&b, "%s %s %s\n", step.Action, step.Commit.Hash, step.Commit.Message)
+	}
+
+	file, err := os.CreateTemp("", "smak-rebase-todo-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(b.String()); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// RebaseCommand prepares `git rebase -i base` with the todo list replaced by
+// the file at todoPath. Editors for reword/squash messages still open, so the
+// command has to run attached to the terminal.
+func RebaseCommand(base, todoPath string) *exec.Cmd {
+	cmd := gitCommand("rebase", "-i", base)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoPath))
+	return cmd
+}
+
+// ContinueRebaseCommand prepares `git rebase --continue` after staging the
+// files whose conflicts were resolved.
+func ContinueRebaseCommand() (*exec.Cmd, *MergeResult, error) {
+	if result, err := stageResolvedFiles(); err != nil || result != nil {
+		return nil, result, err
+	}
+	return gitCommand("rebase", "--continue"), nil, nil
+}
+
+func SkipRebaseCommand() *exec.Cmd {
+	return gitCommand("rebase", "--skip")
+}
+
+func AbortRebase() error {
+	cmd := gitCommand("rebase", "--abort")
+	return cmd.Run()
+}
+
+// GetRebaseState reports whether an interactive rebase is stopped, where,
+// and with which conflicts.
+func GetRebaseState() (*RebaseState, error) {
+	dir, err := gitPath("rebase-merge")
+	if err != nil {
+		return nil, err
+	}
+
+	state := &RebaseState{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return state, nil
+	}
+	state.InProgress = true
+
+	state.Step, _ = strconv.Atoi(readTrimmed(filepath.Join(dir, "msgnum")))
+	state.Total, _ = strconv.Atoi(readTrimmed(filepath.Join(dir, "end")))
+	state.StoppedAt = readTrimmed(filepath.Join(dir, "stopped-sha"))
+
+	conflictFiles, err := GetConflictFiles()
+	if err != nil {
+		return nil, err
+	}
+	state.ConflictFiles = conflictFiles
+
+	return state, nil
+}
+
+// gitPath resolves a path inside the git directory, which is not always
+// .git (worktrees, submodules).
+func gitPath(name string) (string, error) {
+	cmd := gitCommand("rev-parse", "--git-path", name)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func readTrimmed(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// defaultProtectedBranches are used when smak.protectedBranches is not set.
+var defaultProtectedBranches = []string{"main", "master", "develop"}
+
+// GetCommitMessage returns the full message (subject and body) of a commit.
+func GetCommitMessage(hash string) (string, error) {
+	cmd := gitCommand("log", "-1", "--format=%B", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// ProtectedRefsContaining lists the remote branches that contain the commit
+// and are protected (git config smak.protectedBranches, space separated).
+// Rewriting such a commit would rewrite shared history.
+func ProtectedRefsContaining(hash string) ([]string, error) {
+	protected := defaultProtectedBranches
+	if configured := GetConfig("smak.protectedBranches"); configured != "" {
+		protected = strings.Fields(configured)
+	}
+
+	cmd := gitCommand("branch", "-r", "--contains", hash, "--format=%(refname:short)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	for _, ref := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		_, name, ok := strings.Cut(ref, "/")
+		if !ok {
+			continue
+		}
+		for _, branch := range protected {
+			if name == branch {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs, nil
+}
+
+// RewordCommit replaces the message of a commit on the current branch. HEAD
+// is amended; older commits are rewritten with a non-interactive rebase that
+// amends the message right after the commit is picked.
+func RewordCommit(hash, message string) error {
+	messageFile, err := os.CreateTemp("", "smak-message-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(messageFile.Name())
+	if _, err := messageFile.WriteString(message + "\n"); err != nil {
+		messageFile.Close()
+		return err
+	}
+	messageFile.Close()
+
+	head, err := revParse("HEAD")
+	if err != nil {
+		return err
+	}
+	if head == hash {
+		// --only keeps whatever is staged out of the amended commit
+		cmd := gitCommand("commit", "--amend", "--only", "--no-verify", "--allow-empty", "-F", messageFile.Name())
+		return runWithOutput(cmd)
+	}
+
+	base, commits, err := rebaseRange(hash, "reword")
+	if err != nil {
+		return err
+	}
+
+	var todo strings.Builder
+	for i := len(commits) - 1; i >= 0; i-- {
+		fmt.Fprintf(&todo, "pick %s %s\n", commits[i].Hash, commits[i].Message)
+		if commits[i].Hash == hash {
+			fmt.Fprintf(&todo, "exec git commit --amend --only --no-verify --allow-empty -F %s\n", shellQuote(messageFile.Name()))
+		}
+	}
+
+	return runScriptedRebase(todo.String(), base...)
+}
+
+// rebaseRange returns the arguments that make a rebase start right before
+// hash, and the commits it would replay (newest first). Ranges containing
+// merges are refused since the rebase would flatten them.
+func rebaseRange(hash, action string) ([]string, []Commit, error) {
+	commit, err := getCommits("-1", hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(commit) == 0 {
+		return nil, nil, fmt.Errorf("commit %s not found", hash)
+	}
+
+	var base []string
+	var commits []Commit
+	if len(commit[0].Parents) == 0 {
+		base = []string{"--root"}
+		commits, err = getCommits("HEAD")
+	} else {
+		base = []string{hash + "^"}
+		commits, err = getCommits(hash + "^..HEAD")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			return nil, nil, fmt.Errorf("cannot %s across merge commit %s", action, c.Hash[:8])
+		}
+	}
+	return base, commits, nil
+}
+
+// CreateFixup commits the staged changes as a "fixup!" commit for hash.
+func CreateFixup(hash string) error {
+	staged, err := HasStagedChanges()
+	if err != nil {
+		return err
+	}
+	if !staged {
+		return fmt.Errorf("nothing staged to fix up %s with", shortRev(hash))
+	}
+	return runWithOutput(gitCommand("commit", "--fixup="+hash))
+}
+
+// Autosquash folds the fixup! and squash! commits made since hash into
+// their targets with a non-interactive rebase. The rebase is aborted again
+// if it stops, leaving the fixup commits in place.
+func Autosquash(hash string) error {
+	base, _, err := rebaseRange(hash, "autosquash")
+	if err != nil {
+		return err
+	}
+
+	cmd := gitCommand(append([]string{"rebase", "-i", "--autosquash", "--autostash"}, base...)...)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true")
+	return runRebase(cmd)
+}
+
+// HasStagedChanges reports whether the index differs from HEAD.
+func HasStagedChanges() (bool, error) {
+	err := gitCommand("diff", "--cached", "--quiet").Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
+func shortRev(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
+// runScriptedRebase runs `git rebase -i` with a prepared todo list and no
+// editors, aborting it again if anything goes wrong.
+func runScriptedRebase(todo string, args ...string) error {
+	todoFile, err := os.CreateTemp("", "smak-rebase-todo-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(todoFile.Name())
+	if _, err := todoFile.WriteString(todo); err != nil {
+		todoFile.Close()
+		return err
+	}
+	todoFile.Close()
+
+	cmd := gitCommand(append([]string{"rebase", "-i", "--autostash"}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile.Name()),
+		"GIT_EDITOR=true",
+	)
+	return runRebase(cmd)
+}
+
+// runRebase runs a rebase that needs no input, aborting it if it stops.
+func runRebase(cmd *exec.Cmd) error {
+	if err := runWithOutput(cmd); err != nil {
+		if state, stateErr := GetRebaseState(); stateErr == nil && state.InProgress {
+			AbortRebase()
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReflogEntry is one step in the history of where a ref pointed. Selector
+// is the HEAD@{n} (or branch@{n}) name of the entry and Subject the raw
+// reflog message, e.g. "checkout: moving from main to feature".
+type ReflogEntry struct {
+	Selector      string
+	Hash          string
+	Date          time.Time
+	Subject       string
+	CommitSubject string
+}
+
+// Reset modes offered when moving a branch back to a reflog entry.
+const (
+	ResetSoft  = "soft"
+	ResetMixed = "mixed"
+	ResetHard  = "hard"
+)
+
+// GetReflog lists the reflog of ref (HEAD or a branch), newest first.
+func GetReflog(ref string) ([]ReflogEntry, error) {
+	cmd := gitCommand("reflog", "show", "--format=%gd%x00%H%x00%ct%x00%gs%x00%s", ref, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ReflogEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\x00", 5)
+		if len(parts) != 5 {
+			continue
+		}
+		entry := ReflogEntry{Selector: parts[0], Hash: parts[1], Subject: parts[3], CommitSubject: parts[4]}
+		if seconds, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			entry.Date = time.Unix(seconds, 0)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Description explains in plain words what the operation that created the
+// entry did.
+func (e ReflogEntry) Description() string {
+	action, message, ok := strings.Cut(e.Subject, ": ")
+	if !ok {
+		return e.Subject
+	}
+	// Older git writes "rebase -i (pick)" where newer writes "rebase (pick)"
+	action = strings.Replace(action, " -i (", " (", 1)
+
+	quoted := "“" + message + "”"
+	switch {
+	case action == "commit":
+		return "Committed " + quoted
+	case action == "commit (initial)":
+		return "Created the first commit " + quoted
+	case action == "commit (amend)":
+		return "Amended the last commit, now " + quoted
+	case action == "commit (merge)":
+		return "Committed the merge " + quoted
+	case action == "checkout":
+		if from, to, ok := strings.Cut(strings.TrimPrefix(message, "moving from "), " to "); ok {
+			return "Switched from " + from + " to " + to
+		}
+	case action == "reset":
+		target := strings.TrimPrefix(message, "moving to ")
+		if len(target) == 40 && strings.Trim(target, "0123456789abcdef") == "" {
+			target = shortRev(target)
+		}
+		return "Reset to " + target
+	case action == "rebase (start)":
+		return "Started a rebase onto " + strings.TrimPrefix(message, "checkout ")
+	case action == "rebase (finish)":
+		return "Finished rebasing " + strings.TrimPrefix(strings.TrimPrefix(message, "returning to "), "refs/heads/")
+	case action == "rebase (abort)":
+		return "Aborted the rebase, back on " + strings.TrimPrefix(strings.TrimPrefix(message, "returning to "), "refs/heads/")
+	case strings.HasPrefix(action, "rebase ("):
+		step := strings.TrimSuffix(strings.TrimPrefix(action, "rebase ("), ")")
+		return "Rebase: " + step + " " + quoted
+	case action == "cherry-pick":
+		return "Cherry-picked " + quoted
+	case action == "revert":
+		return "Reverted, committing " + quoted
+	case strings.HasPrefix(action, "merge "):
+		branch := strings.TrimPrefix(action, "merge ")
+		if message == "Fast-forward" {
+			return "Fast-forwarded to " + branch
+		}
+		return "Merged " + branch
+	case strings.HasPrefix(action, "pull"):
+		if message == "Fast-forward" {
+			return "Pulled (fast-forward)"
+		}
+		return "Pulled: " + message
+	case action == "branch":
+		return "Created the branch " + strings.TrimPrefix(message, "Created ")
+	case action == "Branch":
+		return "Renamed " + strings.ReplaceAll(strings.TrimPrefix(message, "renamed "), "refs/heads/", "")
+	case action == "clone":
+		return "Cloned " + strings.TrimPrefix(message, "from ")
+	}
+	return e.Subject
+}
+
+// ResetTo moves the current branch to hash. Soft keeps the index and working
+// tree, mixed keeps the working tree and hard discards every change.
+func ResetTo(hash, mode string) error {
+	return runWithOutput(gitCommand("reset", "--"+mode, hash))
+}
+
+// CreateBranchAt creates a branch pointing at hash without switching to it.
+func CreateBranchAt(name, hash string) error {
+	if err := runWithOutput(gitCommand("check-ref-format", "--branch", name)); err != nil {
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+	return runWithOutput(gitCommand("branch", name, hash))
+}
+
+// GetDiffBetween returns the patch that turns from into to.
+func GetDiffBetween(from, to string) (string, error) {
+	output, err := gitCommand("diff", "--no-color", from, to, "--").Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
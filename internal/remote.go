@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// DefaultRemoteKey is the git config key naming the remote smak pushes new
+// branches and tags to and compares branches without an upstream against.
+const DefaultRemoteKey = "smak.defaultRemote"
+
+// Remote is a configured remote. PushURL is empty when pushes go to
+// FetchURL.
+type Remote struct {
+	Name          string
+	FetchURL      string
+	PushURL       string
+	FetchRefspecs []string
+	PushRefspecs  []string
+	Default       bool
+}
+
+// remoteNames lists the configured remotes in config order.
+func remoteNames() ([]string, error) {
+	output, err := gitCommand("remote").Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// DefaultRemote returns the remote set with smak.defaultRemote. Without one
+// it is origin, or the first remote when there is no origin.
+func DefaultRemote() string {
+	if remote := GetConfig(DefaultRemoteKey); remote != "" {
+		return remote
+	}
+	names, _ := remoteNames()
+	if len(names) == 0 || slices.Contains(names, "origin") {
+		return "origin"
+	}
+	return names[0]
+}
+
+// GetRemotes lists the remotes with their URLs and refspecs.
+func GetRemotes() ([]Remote, error) {
+	names, err := remoteNames()
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]Remote, len(names))
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		remotes[i] = Remote{Name: name}
+		index[name] = i
+	}
+	if len(remotes) == 0 {
+		return remotes, nil
+	}
+
+	// A single config read covers every remote; it exits 1 when nothing matches
+	output, _ := gitCommand("config", "--get-regexp", `^remote\.`).Output()
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		// Remote names may contain dots, the setting name cannot
+		dot := strings.LastIndex(key, ".")
+		if dot < len("remote.") {
+			continue
+		}
+		i, ok := index[key[len("remote."):dot]]
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key[dot+1:]) {
+		case "url":
+			remotes[i].FetchURL = value
+		case "pushurl":
+			remotes[i].PushURL = value
+		case "fetch":
+			remotes[i].FetchRefspecs = append(remotes[i].FetchRefspecs, value)
+		case "push":
+			remotes[i].PushRefspecs = append(remotes[i].PushRefspecs, value)
+		}
+	}
+
+	defaultRemote := DefaultRemote()
+	for i := range remotes {
+		remotes[i].Default = remotes[i].Name == defaultRemote
+	}
+	return remotes, nil
+}
+
+// AddRemote adds a remote that fetches every branch from url.
+func AddRemote(name, url string) error {
+	return runWithOutput(gitCommand("remote", "add", name, url))
+}
+
+// RemoveRemote removes a remote and its remote-tracking branches, and
+// forgets it as the default remote.
+func RemoveRemote(name string) error {
+	if err := runWithOutput(gitCommand("remote", "remove", name)); err != nil {
+		return err
+	}
+	if GetConfig(DefaultRemoteKey) == name {
+		return runWithOutput(gitCommand("config", "--unset", DefaultRemoteKey))
+	}
+	return nil
+}
+
+// RenameRemote renames a remote, its remote-tracking branches and the
+// upstreams pointing at it, keeping it the default remote if it was.
+func RenameRemote(oldName, newName string) error {
+	if err := runWithOutput(gitCommand("remote", "rename", oldName, newName)); err != nil {
+		return err
+	}
+	if GetConfig(DefaultRemoteKey) == oldName {
+		return SetDefaultRemote(newName)
+	}
+	return nil
+}
+
+// SetDefaultRemote stores the default remote in the repository's config.
+func SetDefaultRemote(name string) error {
+	return runWithOutput(gitCommand("config", DefaultRemoteKey, name))
+}
+
+// FetchRemoteCommand builds a fetch of one remote that drops its
+// remote-tracking branches deleted on the remote.
+func FetchRemoteCommand(name string) *exec.Cmd {
+	return gitCommand("fetch", "--prune", "--progress", name)
+}
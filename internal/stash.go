@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stash is an entry of `git stash list`. Ref is the stash@{n} name, which
+// shifts as stashes are created and dropped.
+type Stash struct {
+	Ref     string
+	Hash    string
+	Branch  string
+	Message string
+	Date    time.Time
+}
+
+func GetStashes() ([]Stash, error) {
+	cmd := gitCommand("stash", "list", "--format=%gd%x00%H%x00%ct%x00%gs")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\x00", 4)
+		if len(parts) != 4 {
+			continue
+		}
+
+		stash := Stash{Ref: parts[0], Hash: parts[1], Message: parts[3]}
+		if seconds, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			stash.Date = time.Unix(seconds, 0)
+		}
+
+		// The subject reads "WIP on <branch>: <hash> <subject>" or "On <branch>: <message>"
+		subject := parts[3]
+		for _, prefix := range []string{"WIP on ", "On "} {
+			if rest, ok := strings.CutPrefix(subject, prefix); ok {
+				subject = rest
+				break
+			}
+		}
+		if branch, message, ok := strings.Cut(subject, ": "); ok {
+			stash.Branch = branch
+			stash.Message = message
+		}
+		stashes = append(stashes, stash)
+	}
+
+	return stashes, nil
+}
+
+// GetStashDiff returns the changes saved in a stash, including untracked
+// files when the git version supports showing them.
+func GetStashDiff(ref string) (string, error) {
+	output, err := gitCommand("stash", "show", "-p", "--no-color", "--include-untracked", ref).Output()
+	if err != nil {
+		output, err = gitCommand("stash", "show", "-p", "--no-color", ref).Output()
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// CreateStash stashes all changes, untracked files included.
+func CreateStash(message string) error {
+	args := []string{"stash", "push", "--include-untracked"}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+	return runWithOutput(gitCommand(args...))
+}
+
+// ApplyStash applies a stash to the working tree; pop also drops it when it
+// applied cleanly.
+func ApplyStash(ref string, pop bool) *MergeResult {
+	action := "apply"
+	if pop {
+		action = "pop"
+	}
+	output, err := gitCommand("stash", action, ref).CombinedOutput()
+	return resultFromOutput(output, err)
+}
+
+func DropStash(ref string) error {
+	return runWithOutput(gitCommand("stash", "drop", ref))
+}
+
+// RenameStash replaces the message of a stash. Git cannot edit a stash in
+// place, so it is stored again under the new message, which puts it at
+// stash@{0}, and only then is the old entry dropped.
+func RenameStash(stash Stash, message string) error {
+	var index int
+	if _, err := fmt.Sscanf(stash.Ref, "stash@{%d}", &index); err != nil {
+		return fmt.Errorf("unexpected stash ref %s", stash.Ref)
+	}
+	if err := runWithOutput(gitCommand("stash", "store", "-m", "On "+stash.Branch+": "+message, stash.Hash)); err != nil {
+		return err
+	}
+
+	// Storing pushed the old entry down by one. A dry run stored nothing, so
+	// there is nothing to check
+	old := fmt.Sprintf("stash@{%d}", index+1)
+	if hash, err := revParse(old); !DryRun && (err != nil || hash != stash.Hash) {
+		return fmt.Errorf("stored the renamed stash, but %s is not the original entry; drop the old one by hand", old)
+	}
+	return DropStash(old)
+}
+
+// StashBranch creates a branch at the commit the stash was made on, checks
+// it out and pops the stash onto it.
+func StashBranch(name, ref string) error {
+	return runWithOutput(gitCommand("stash", "branch", name, ref))
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// StatusEntry is one path from `git status`. Index and Worktree hold the
+// porcelain status letters ('M', 'A', 'D', 'R', '?', 'U', ' ', ...).
+type StatusEntry struct {
+	Path     string
+	OldPath  string
+	Index    byte
+	Worktree byte
+}
+
+func (e StatusEntry) Untracked() bool {
+	return e.Index == '?'
+}
+
+func (e StatusEntry) Conflicted() bool {
+	return e.Index == 'U' || e.Worktree == 'U' ||
+		(e.Index == 'A' && e.Worktree == 'A') || (e.Index == 'D' && e.Worktree == 'D')
+}
+
+// Staged reports whether the index differs from HEAD for this path.
+func (e StatusEntry) Staged() bool {
+	return !e.Untracked() && !e.Conflicted() && e.Index != ' '
+}
+
+// Unstaged reports whether the working tree differs from the index.
+func (e StatusEntry) Unstaged() bool {
+	return !e.Untracked() && !e.Conflicted() && e.Worktree != ' '
+}
+
+// GetStatus lists every changed, untracked and conflicted path.
+func GetStatus() ([]StatusEntry, error) {
+	cmd := gitCommand("status", "--porcelain=v1", "-z", "--untracked-files=all")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []StatusEntry
+	fields := strings.Split(strings.TrimRight(string(output), "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if len(field) < 4 {
+			continue
+		}
+
+		entry := StatusEntry{
+			Index:    field[0],
+			Worktree: field[1],
+			Path:     field[3:],
+		}
+		// Renames and copies are followed by the original path
+		if entry.Index == 'R' || entry.Index == 'C' {
+			if i+1 < len(fields) {
+				entry.OldPath = fields[i+1]
+				i++
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// GetWorktreeDiff returns the unstaged changes of a path. Untracked files are
+// diffed against /dev/null so they show up as entirely added.
+func GetWorktreeDiff(entry StatusEntry) (string, error) {
+	var cmd *exec.Cmd
+	if entry.Untracked() {
+		cmd = gitCommand("diff", "--no-color", "--no-index", "--", "/dev/null", entry.Path)
+	} else {
+		cmd = gitCommand("diff", "--no-color", "--", entry.Path)
+	}
+
+	output, err := cmd.Output()
+	// --no-index exits with 1 when the files differ
+	if err != nil && len(output) == 0 {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// GetStagedDiff returns the staged changes of a path.
+func GetStagedDiff(entry StatusEntry) (string, error) {
+	args := []string{"diff", "--no-color", "--cached", "-M", "--"}
+	if entry.OldPath != "" {
+		args = append(args, entry.OldPath)
+	}
+	args = append(args, entry.Path)
+
+	cmd := gitCommand(args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// StageFile stages every change to a path, including deletions. For a
+// conflicted path this marks it as resolved.
+func StageFile(path string) error {
+	cmd := gitCommand("add", "-A", "--", path)
+	return runWithOutput(cmd)
+}
+
+// StageHunks stages only the given hunks of a file diff (as produced by
+// GetWorktreeDiff).
+func StageHunks(fileDiff FileDiff, hunks []int) error {
+	return applyHunks(fileDiff, hunks, "--cached")
+}
+
+// UnstageFile removes the staged changes of a path from the index and keeps
+// the working tree as it is.
+func UnstageFile(entry StatusEntry) error {
+	paths := []string{entry.Path}
+	if entry.OldPath != "" {
+		paths = append(paths, entry.OldPath)
+	}
+
+	if _, err := revParse("HEAD"); err != nil {
+		// Without a first commit there is nothing to restore from
+		return runWithOutput(gitCommand(append([]string{"rm", "--cached", "-r", "-q", "--"}, paths...)...))
+	}
+	return runWithOutput(gitCommand(append([]string{"restore", "--staged", "--"}, paths...)...))
+}
+
+// UnstageAll empties the index back to HEAD.
+func UnstageAll() error {
+	return UnstageFile(StatusEntry{Path: ":/"})
+}
+
+// UnstageHunks takes the given hunks of a staged file diff (as produced by
+// GetStagedDiff) back out of the index.
+func UnstageHunks(fileDiff FileDiff, hunks []int) error {
+	return applyHunks(fileDiff, hunks, "--cached", "--reverse")
+}
+
+// DiscardFile throws away the unstaged changes of a path. Untracked files
+// are deleted.
+func DiscardFile(entry StatusEntry) error {
+	if entry.Untracked() {
+		return runWithOutput(gitCommand("clean", "-f", "-q", "--", entry.Path))
+	}
+	return runWithOutput(gitCommand("restore", "--worktree", "--", entry.Path))
+}
+
+// DiscardHunks reverts the given hunks of an unstaged file diff in the
+// working tree.
+func DiscardHunks(fileDiff FileDiff, hunks []int) error {
+	return applyHunks(fileDiff, hunks, "--reverse")
+}
+
+// applyHunks feeds a patch made of the file header and the chosen hunks to
+// `git apply` with the given extra arguments.
+func applyHunks(fileDiff FileDiff, hunks []int, args ...string) error {
+	patch := BuildPatch(fileDiff, hunks)
+	cmd := gitCommand(append([]string{"apply", "--unidiff-zero", "--whitespace=nowarn"}, append(args, "-")...)...)
+	cmd.Stdin = strings.NewReader(patch)
+	return runWithOutput(cmd)
+}
+
+// BuildPatch keeps the header of a file diff and only the selected hunks.
+func BuildPatch(fileDiff FileDiff, hunks []int) string {
+	selected := make(map[int]bool)
+	for _, idx := range hunks {
+		selected[idx] = true
+	}
+
+	var b strings.Builder
+	for _, line := range fileDiff.Header() {
+		b.WriteString(line + "\n")
+	}
+
+	hunk := -1
+	for _, line := range fileDiff.Lines[len(fileDiff.Header()):] {
+		if strings.HasPrefix(line, "@@") {
+			hunk++
+		}
+		if selected[hunk] {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
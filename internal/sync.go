@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Outcomes of syncing a branch with its upstream.
+const (
+	SyncUpToDate      = "up to date"
+	SyncFastForwarded = "fast-forwarded"
+	SyncAhead         = "ahead"
+	SyncDiverged      = "diverged"
+	SyncNoUpstream    = "no upstream"
+	SyncUpstreamGone  = "upstream gone"
+	SyncFailed        = "failed"
+)
+
+// SyncResult is what happened to one local branch during a sync. From and
+// To are set when the branch was fast-forwarded.
+type SyncResult struct {
+	Branch   string
+	Upstream string
+	Status   string
+	Ahead    int
+	Behind   int
+	From     string
+	To       string
+	Err      error
+}
+
+// FetchAllCommand builds a fetch of every remote that drops remote-tracking
+// branches deleted on the remote.
+func FetchAllCommand() *exec.Cmd {
+	return gitCommand("fetch", "--all", "--prune", "--progress")
+}
+
+// FetchAll fetches every remote and returns git's report.
+func FetchAll() (string, error) {
+	output, err := FetchAllCommand().CombinedOutput()
+	text := strings.TrimSpace(string(output))
+	if err != nil {
+		if text != "" {
+			return text, fmt.Errorf("%w: %s", err, text)
+		}
+		return text, err
+	}
+	return text, nil
+}
+
+// SyncBranches fast-forwards every local branch that is only behind its
+// upstream, without checking it out, and reports the state of the others.
+// It works on the remote-tracking branches as they are, so fetch first.
+func SyncBranches() ([]SyncResult, error) {
+	branches, err := GetBranches()
+	if err != nil {
+		return nil, err
+	}
+	current, _ := CurrentBranch()
+
+	results := make([]SyncResult, 0, len(branches))
+	for _, branch := range branches {
+		result := SyncResult{
+			Branch:   branch.Name,
+			Upstream: branch.Upstream,
+			Ahead:    branch.CommitsAhead,
+			Behind:   branch.CommitsBehind,
+		}
+
+		switch {
+		case branch.Upstream == "":
+			result.Status = SyncNoUpstream
+		case branch.UpstreamGone:
+			result.Status = SyncUpstreamGone
+			result.Ahead, result.Behind = 0, 0
+		case branch.CommitsBehind == 0 && branch.CommitsAhead == 0:
+			result.Status = SyncUpToDate
+		case branch.CommitsBehind == 0:
+			result.Status = SyncAhead
+		case branch.CommitsAhead > 0:
+			result.Status = SyncDiverged
+		default:
+			result.From = branch.Hash
+			result.To, result.Err = fastForward(branch, branch.Name == current)
+			result.Status = SyncFastForwarded
+			if result.Err != nil {
+				result.Status = SyncFailed
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// fastForward moves a branch to its upstream. A branch that is checked out
+// somewhere is merged in that worktree so its files follow along; any other
+// branch only has its ref moved.
+func fastForward(branch Branch, checkedOutHere bool) (string, error) {
+	to, err := revParse(branch.Upstream + "^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s", branch.Upstream)
+	}
+
+	switch {
+	case checkedOutHere:
+		err = runWithOutput(gitCommand("merge", "--ff-only", branch.Upstream))
+	case branch.Worktree != "":
+		err = runWithOutput(gitCommand("-C", branch.Worktree, "merge", "--ff-only", branch.Upstream))
+	default:
+		// Passing the old value makes git refuse if the branch moved meanwhile
+		err = runWithOutput(gitCommand("update-ref", "-m", "sync: fast-forward to "+branch.Upstream,
+			"refs/heads/"+branch.Name, to, branch.Hash))
+	}
+	if err != nil {
+		return "", err
+	}
+	return to, nil
+}
+
+// PullBranchCommand builds a fast-forward of a branch to its upstream that
+// fetches first. A branch that is checked out is pulled in its worktree;
+// any other branch is fetched straight into its ref, which git refuses
+// unless it is a fast-forward.
+func PullBranchCommand(branch Branch, checkedOutHere bool) (*exec.Cmd, error) {
+	remote := GetConfig("branch." + branch.Name + ".remote")
+	merge := GetConfig("branch." + branch.Name + ".merge")
+	if remote == "" || merge == "" {
+		return nil, fmt.Errorf("%s has no upstream to pull from", branch.Name)
+	}
+
+	switch {
+	case checkedOutHere:
+		return gitCommand("pull", "--ff-only", "--progress"), nil
+	case branch.Worktree != "":
+		return gitCommand("-C", branch.Worktree, "pull", "--ff-only", "--progress"), nil
+	}
+	return gitCommand("fetch", "--progress", remote, merge+":refs/heads/"+branch.Name), nil
+}
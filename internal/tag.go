@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag is a lightweight or annotated tag. Commit is the commit it points at;
+// for annotated tags Subject and Body hold the annotation, for lightweight
+// ones Subject is the commit subject.
+type Tag struct {
+	Name          string
+	Commit        string
+	CommitSubject string
+	Annotated     bool
+	Signed        bool
+	Tagger        string
+	TaggerEmail   string
+	Date          time.Time
+	Subject       string
+	Body          string
+}
+
+// Tag sort orders accepted by GetTags.
+const (
+	TagSortVersion = "version"
+	TagSortDate    = "date"
+)
+
+// GetTags lists all tags, newest version or newest date first.
+func GetTags(sortBy string) ([]Tag, error) {
+	sortKey := "-v:refname"
+	if sortBy == TagSortDate {
+		sortKey = "-creatordate"
+	}
+
+	// Records are separated by 0x1e since annotation bodies span lines
+	format := strings.Join([]string{
+		"%(refname:short)", "%(objecttype)", "%(objectname)", "%(*objectname)",
+		"%(*subject)", "%(creatordate:unix)", "%(taggername)", "%(taggeremail:trim)",
+		"%(contents:subject)", "%(contents:body)", "%(contents:signature)",
+	}, "%00") + "%1e"
+	cmd := gitCommand("for-each-ref", "--sort="+sortKey, "--format="+format, "refs/tags")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	for _, record := range strings.Split(string(output), "\x1e") {
+		record = strings.TrimPrefix(record, "\n")
+		parts := strings.Split(record, "\x00")
+		if len(parts) != 11 {
+			continue
+		}
+
+		tag := Tag{
+			Name:        parts[0],
+			Commit:      parts[2],
+			Annotated:   parts[1] == "tag",
+			Tagger:      parts[6],
+			TaggerEmail: parts[7],
+			Subject:     parts[8],
+			Body:        strings.TrimSpace(parts[9]),
+			Signed:      parts[10] != "",
+		}
+		if seconds, err := strconv.ParseInt(parts[5], 10, 64); err == nil {
+			tag.Date = time.Unix(seconds, 0)
+		}
+		if tag.Annotated {
+			// Tags of tags are rare enough to simply show the object they point at
+			if parts[3] != "" {
+				tag.Commit = parts[3]
+			}
+			tag.CommitSubject = parts[4]
+		} else {
+			tag.CommitSubject = parts[8]
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// TagOptions describes a tag to create. An empty Commit tags HEAD.
+type TagOptions struct {
+	Name    string
+	Commit  string
+	Message string
+	Sign    bool
+}
+
+// CreateTag creates an annotated tag, signed with the user's key when Sign
+// is set.
+func CreateTag(opts TagOptions) error {
+	if strings.TrimSpace(opts.Message) == "" {
+		return fmt.Errorf("an annotated tag needs a message")
+	}
+	if err := runWithOutput(gitCommand("check-ref-format", "refs/tags/"+opts.Name)); err != nil {
+		return fmt.Errorf("%q is not a valid tag name", opts.Name)
+	}
+
+	commit := opts.Commit
+	if commit == "" {
+		commit = "HEAD"
+	}
+	hash, err := ResolveCommit(commit)
+	if err != nil {
+		return err
+	}
+
+	flag := "--annotate"
+	if opts.Sign {
+		flag = "--sign"
+	}
+	return runWithOutput(gitCommand("tag", flag, "--message="+opts.Message, opts.Name, hash))
+}
+
+func DeleteTags(names []string) error {
+	return runWithOutput(gitCommand(append([]string{"tag", "--delete"}, names...)...))
+}
+
+// DeleteRemoteTags deletes tags from a remote; the local tags are left alone.
+func DeleteRemoteTags(remote string, names []string) error {
+	args := []string{"push", "--delete", remote}
+	for _, name := range names {
+		args = append(args, "refs/tags/"+name)
+	}
+	return runWithOutput(gitCommand(args...))
+}
+
+func PushTags(remote string, names []string) error {
+	args := []string{"push", remote}
+	for _, name := range names {
+		args = append(args, "refs/tags/"+name)
+	}
+	return runWithOutput(gitCommand(args...))
+}
+
+var versionRe = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(-[0-9A-Za-z.-]+)?$`)
+
+// Version is a semantic version tag such as v1.4.2 or 2.0.0-rc.1. Prefix is
+// the optional leading "v", kept so bumped versions match the existing tags.
+type Version struct {
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+}
+
+func ParseVersion(name string) (Version, bool) {
+	match := versionRe.FindStringSubmatch(name)
+	if match == nil {
+		return Version{}, false
+	}
+	major, _ := strconv.Atoi(match[2])
+	minor, _ := strconv.Atoi(match[3])
+	patch, _ := strconv.Atoi(match[4])
+	return Version{
+		Prefix:     match[1],
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: strings.TrimPrefix(match[5], "-"),
+	}, true
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	return s
+}
+
+// Less orders versions by precedence, a pre-release coming before its release.
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	if v.Patch != other.Patch {
+		return v.Patch < other.Patch
+	}
+	if v.PreRelease == "" || other.PreRelease == "" {
+		return v.PreRelease != "" && other.PreRelease == ""
+	}
+	return preReleaseLess(v.PreRelease, other.PreRelease)
+}
+
+// preReleaseLess compares pre-release versions the way semver does: by their
+// dot-separated identifiers, numeric ones numerically (rc.2 < rc.10) and
+// before alphanumeric ones, and a shorter list before a longer one it starts.
+func preReleaseLess(a, b string) bool {
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if left[i] == right[i] {
+			continue
+		}
+		leftNum, leftErr := strconv.ParseUint(left[i], 10, 64)
+		rightNum, rightErr := strconv.ParseUint(right[i], 10, 64)
+		switch {
+		case leftErr == nil && rightErr == nil:
+			return leftNum < rightNum
+		case leftErr == nil || rightErr == nil:
+			return leftErr == nil
+		}
+		return left[i] < right[i]
+	}
+	return len(left) < len(right)
+}
+
+// Version bump kinds, in the order they are offered.
+var VersionBumps = []string{"patch", "minor", "major"}
+
+// Bump returns the next release version for a "patch", "minor" or "major"
+// bump. Bumping a pre-release patch gives its release (1.2.0-rc.1 -> 1.2.0).
+func (v Version) Bump(kind string) Version {
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch kind {
+	case "major":
+		if v.PreRelease == "" || v.Minor != 0 || v.Patch != 0 {
+			next.Major++
+		}
+		next.Minor, next.Patch = 0, 0
+	case "minor":
+		if v.PreRelease == "" || v.Patch != 0 {
+			next.Minor++
+		}
+		next.Patch = 0
+	default:
+		if v.PreRelease == "" {
+			next.Patch++
+		}
+	}
+	return next
+}
+
+// LatestVersion returns the highest semantic version among the tags. Without
+// any, it returns v0.0.0 so bumps still give sensible first versions.
+func LatestVersion(tags []Tag) (Version, bool) {
+	latest, found := Version{Prefix: "v"}, false
+	for _, tag := range tags {
+		if version, ok := ParseVersion(tag.Name); ok && (!found || latest.Less(version)) {
+			latest, found = version, true
+		}
+	}
+	return latest, found
+}
@@ -0,0 +1,91 @@
+package internal
+
+import "testing"
+
+func mustParseVersion(t *testing.T, name string) Version {
+	t.Helper()
+	version, ok := ParseVersion(name)
+	if !ok {
+		t.Fatalf("ParseVersion(%q) failed", name)
+	}
+	return version
+}
+
+func TestVersionLess(t *testing.T) {
+	// The precedence example from the semver spec, lowest first
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := mustParseVersion(t, ordered[i]), mustParseVersion(t, ordered[j])
+			if got, want := a.Less(b), i < j; got != want {
+				t.Errorf("%s < %s = %v, want %v", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestLatestVersionNumericPreRelease(t *testing.T) {
+	tags := []Tag{{Name: "v1.0.0-rc.10"}, {Name: "v1.0.0-rc.2"}, {Name: "v1.0.0-rc.9"}}
+	latest, ok := LatestVersion(tags)
+	if !ok || latest.String() != "v1.0.0-rc.10" {
+		t.Errorf("LatestVersion = %s, %v, want v1.0.0-rc.10", latest, ok)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		want Version
+		ok   bool
+	}{
+		{"v1.4.2", Version{Prefix: "v", Major: 1, Minor: 4, Patch: 2}, true},
+		{"2.0.0-rc.1", Version{Major: 2, PreRelease: "rc.1"}, true},
+		{"v10.20.30-alpha-1.x", Version{Prefix: "v", Major: 10, Minor: 20, Patch: 30, PreRelease: "alpha-1.x"}, true},
+		{"v1.4", Version{}, false},
+		{"release-1.0.0", Version{}, false},
+		{"v1.0.0+build", Version{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseVersion(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseVersion(%q) = %+v, %v, want %+v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+		if ok && got.String() != tt.name {
+			t.Errorf("ParseVersion(%q).String() = %q", tt.name, got.String())
+		}
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		version, kind, want string
+	}{
+		{"v1.2.3", "patch", "v1.2.4"},
+		{"v1.2.3", "minor", "v1.3.0"},
+		{"v1.2.3", "major", "v2.0.0"},
+		{"1.2.3", "patch", "1.2.4"},
+		{"v1.2.3-rc.1", "patch", "v1.2.3"},
+		{"v1.2.3-rc.1", "minor", "v1.3.0"},
+		{"v1.2.0-rc.1", "minor", "v1.2.0"},
+		{"v1.2.0-rc.1", "major", "v2.0.0"},
+		{"v2.0.0-rc.1", "major", "v2.0.0"},
+		{"v0.0.0", "minor", "v0.1.0"},
+	}
+	for _, tt := range tests {
+		if got := mustParseVersion(t, tt.version).Bump(tt.kind).String(); got != tt.want {
+			t.Errorf("%s %s bump = %s, want %s", tt.version, tt.kind, got, tt.want)
+		}
+	}
+}
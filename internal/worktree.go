@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is an entry of `git worktree list`. Branch is empty for a
+// detached HEAD; Current marks the worktree smak is running in.
+type Worktree struct {
+	Path           string
+	Head           string
+	Branch         string
+	Bare           bool
+	Main           bool
+	Current        bool
+	Dirty          bool
+	Locked         bool
+	LockReason     string
+	Prunable       bool
+	PrunableReason string
+}
+
+// RepositoryRoot returns the top directory of the worktree containing the
+// current directory, which also works inside linked worktrees where .git
+// is a file.
+func RepositoryRoot() (string, error) {
+	output, err := gitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RepositoryPrefix returns the current directory relative to the top of
+// the worktree ("" at the top, "sub/dir/" below it).
+func RepositoryPrefix() (string, error) {
+	output, err := gitCommand("rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func GetWorktrees() ([]Worktree, error) {
+	output, err := gitCommand("worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, err
+	}
+	root, _ := RepositoryRoot()
+
+	var worktrees []Worktree
+	for _, block := range strings.Split(strings.TrimSpace(string(output)), "\n\n") {
+		var worktree Worktree
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				worktree.Path = value
+			case "HEAD":
+				worktree.Head = value
+			case "branch":
+				worktree.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				worktree.Bare = true
+			case "locked":
+				worktree.Locked, worktree.LockReason = true, value
+			case "prunable":
+				worktree.Prunable, worktree.PrunableReason = true, value
+			}
+		}
+		if worktree.Path == "" {
+			continue
+		}
+
+		// The main worktree is always listed first
+		worktree.Main = len(worktrees) == 0
+		worktree.Current = samePath(worktree.Path, root)
+		if !worktree.Bare && !worktree.Prunable {
+			status, err := gitCommand("-C", worktree.Path, "status", "--porcelain").Output()
+			worktree.Dirty = err == nil && len(strings.TrimSpace(string(status))) > 0
+		}
+		worktrees = append(worktrees, worktree)
+	}
+
+	return worktrees, nil
+}
+
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// DefaultWorktreePath suggests a directory for a branch's worktree next to
+// the main worktree, e.g. ../repo-feature-login for feature/login.
+func DefaultWorktreePath(mainPath, branch string) string {
+	name := filepath.Base(mainPath) + "-" + strings.ReplaceAll(branch, "/", "-")
+	return filepath.Join(filepath.Dir(mainPath), name)
+}
+
+// AddWorktree checks out an existing branch in a new worktree at path.
+func AddWorktree(path, branch string) error {
+	return runWithOutput(gitCommand("worktree", "add", path, branch))
+}
+
+// RemoveWorktree deletes a linked worktree; force is needed when it has
+// uncommitted changes.
+func RemoveWorktree(path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	return runWithOutput(gitCommand(append(args, path)...))
+}
+
+// PruneWorktrees drops the administrative data of worktrees whose directory
+// is gone and returns git's report of what was pruned.
+func PruneWorktrees() (string, error) {
+	output, err := gitCommand("worktree", "prune", "--verbose").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// LockWorktree keeps a worktree from being pruned, e.g. while it lives on a
+// removable drive.
+func LockWorktree(path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	return runWithOutput(gitCommand(append(args, path)...))
+}
+
+func UnlockWorktree(path string) error {
+	return runWithOutput(gitCommand("worktree", "unlock", path))
+}